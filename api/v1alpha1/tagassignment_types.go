@@ -0,0 +1,136 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// ConditionReady is true when all tag bindings of the resource are applied in GCP.
+	ConditionReady = "Ready"
+	// ConditionProgressing is true while tag bindings are being created or replaced.
+	ConditionProgressing = "Progressing"
+	// ConditionDegraded is true when the last reconciliation failed.
+	ConditionDegraded = "Degraded"
+)
+
+// ResourceReference identifies the Config Connector resource a TagAssignment belongs to.
+type ResourceReference struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	Name       string `json:"name"`
+}
+
+// TagAssignmentSpec defines the tagged resource of a TagAssignment.
+type TagAssignmentSpec struct {
+	ResourceRef ResourceReference `json:"resourceRef"`
+}
+
+// TagStatus describes a single tag resolved for the resource.
+type TagStatus struct {
+	// Key is the label key the tag was derived from.
+	Key string `json:"key"`
+	// Value is the tag value, after the value transformation of the policy.
+	Value string `json:"value"`
+	// TagKey is the short name of the tag key, if it differs from the label key.
+	// +optional
+	TagKey string `json:"tagKey,omitempty"`
+	// Policy is the TaggingPolicy which mapped the label to the tag. It is empty for tags
+	// derived from --target-labels.
+	// +optional
+	Policy string `json:"policy,omitempty"`
+	// TagValue is the resource name of the resolved TagValue, e.g. tagValues/123.
+	// +optional
+	TagValue string `json:"tagValue,omitempty"`
+	// NamespacedName is the namespaced name of the resolved TagValue, e.g. my-project/env/prod.
+	// +optional
+	NamespacedName string `json:"namespacedName,omitempty"`
+	// BindingName is the name of the generated TagsLocationTagBinding, or TagsTagBinding for global resources.
+	// +optional
+	BindingName string `json:"bindingName,omitempty"`
+	// Ready is true once Config Connector applied the binding.
+	Ready bool `json:"ready"`
+}
+
+// PendingOperation is a long-running operation creating a tag key or value, polled by later reconciliations.
+type PendingOperation struct {
+	// Operation is the name of the long-running operation, e.g. operations/tkc.123.
+	Operation string `json:"operation"`
+	// Kind is TagKey or TagValue.
+	Kind string `json:"kind"`
+	// TagKey is the short name of the tag key.
+	TagKey string `json:"tagKey"`
+	// TagValue is the short name of the tag value, empty while the tag key is created.
+	// +optional
+	TagValue string `json:"tagValue,omitempty"`
+}
+
+// TagAssignmentStatus defines the observed state of TagAssignment
+type TagAssignmentStatus struct {
+	// ProjectID is the project the tag bindings are created in.
+	// +optional
+	ProjectID string `json:"projectID,omitempty"`
+	// Tags lists the tags resolved from the matched labels of the resource.
+	// +optional
+	Tags []TagStatus `json:"tags,omitempty"`
+	// PendingOperations lists the tag keys and values still being created in GCP.
+	// +optional
+	PendingOperations []PendingOperation `json:"pendingOperations,omitempty"`
+	// LastError is the error of the last failed reconciliation.
+	// +optional
+	LastError string `json:"lastError,omitempty"`
+	// ObservedGeneration is the generation of the tagged resource that was last reconciled.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:categories=tagging
+// +kubebuilder:printcolumn:name="Kind",type=string,JSONPath=`.spec.resourceRef.kind`
+// +kubebuilder:printcolumn:name="Resource",type=string,JSONPath=`.spec.resourceRef.name`
+// +kubebuilder:printcolumn:name="Project",type=string,JSONPath=`.status.projectID`
+// +kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=='Ready')].status`
+// +kubebuilder:printcolumn:name="Reason",type=string,JSONPath=`.status.conditions[?(@.type=='Ready')].reason`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// TagAssignment reports the tags applied to a single Config Connector resource
+type TagAssignment struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   TagAssignmentSpec   `json:"spec,omitempty"`
+	Status TagAssignmentStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// TagAssignmentList contains a list of TagAssignment
+type TagAssignmentList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []TagAssignment `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&TagAssignment{}, &TagAssignmentList{})
+}
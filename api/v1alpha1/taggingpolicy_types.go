@@ -0,0 +1,107 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ValueTransform changes a label value before it is used as tag value.
+// +kubebuilder:validation:Enum=None;Lowercase;Uppercase
+type ValueTransform string
+
+const (
+	ValueTransformNone      ValueTransform = "None"
+	ValueTransformLowercase ValueTransform = "Lowercase"
+	ValueTransformUppercase ValueTransform = "Uppercase"
+)
+
+// TagRule maps labels of Config Connector resources to a tag key.
+type TagRule struct {
+	// Label is the label key feeding the tag. Exactly one of label and labelRegex must be set.
+	// +optional
+	Label string `json:"label,omitempty"`
+	// LabelRegex matches the label keys feeding the tag. The whole label key must match.
+	// +optional
+	LabelRegex string `json:"labelRegex,omitempty"`
+	// TagKey is the short name of the tag key, defaulting to the label key.
+	// With labelRegex it may reference capture groups, e.g. "${1}".
+	// +optional
+	TagKey string `json:"tagKey,omitempty"`
+	// ValueTransform is applied to the label value.
+	// +kubebuilder:default=None
+	// +optional
+	ValueTransform ValueTransform `json:"valueTransform,omitempty"`
+	// Kinds limits the rule to these Config Connector kinds, e.g. StorageBucket. Empty matches all kinds.
+	// +optional
+	Kinds []string `json:"kinds,omitempty"`
+	// Namespaces limits the rule to resources in these namespaces. Empty matches all namespaces.
+	// +optional
+	Namespaces []string `json:"namespaces,omitempty"`
+	// Required reports resources the rule applies to, but which lack the label, as failed.
+	// +optional
+	Required bool `json:"required,omitempty"`
+}
+
+// TaggingPolicySpec defines the rules of a TaggingPolicy.
+type TaggingPolicySpec struct {
+	// Rules are evaluated in order. If several rules produce the same tag key, the first one wins.
+	// +kubebuilder:validation:MinItems=1
+	Rules []TagRule `json:"rules"`
+}
+
+// TaggingPolicyStatus defines the observed state of TaggingPolicy
+type TaggingPolicyStatus struct {
+	// MatchedResources is the number of resources tagged by the policy.
+	MatchedResources int32 `json:"matchedResources"`
+	// ObservedGeneration is the generation of the policy that was last validated.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Cluster,categories=tagging
+// +kubebuilder:printcolumn:name="Matched",type=integer,JSONPath=`.status.matchedResources`
+// +kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=='Ready')].status`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// TaggingPolicy declares which labels of Config Connector resources become tags
+type TaggingPolicy struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   TaggingPolicySpec   `json:"spec,omitempty"`
+	Status TaggingPolicyStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// TaggingPolicyList contains a list of TaggingPolicy
+type TaggingPolicyList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []TaggingPolicy `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&TaggingPolicy{}, &TaggingPolicyList{})
+}
@@ -21,13 +21,23 @@ import (
 	"crypto/tls"
 	"flag"
 	"os"
+	"time"
 
 	resourcemanager "cloud.google.com/go/resourcemanager/apiv3"
+	artifactregistryv1beta1 "github.com/GoogleCloudPlatform/k8s-config-connector/pkg/clients/generated/apis/artifactregistry/v1beta1"
+	bigqueryv1beta1 "github.com/GoogleCloudPlatform/k8s-config-connector/pkg/clients/generated/apis/bigquery/v1beta1"
+	computev1beta1 "github.com/GoogleCloudPlatform/k8s-config-connector/pkg/clients/generated/apis/compute/v1beta1"
+	containerv1beta1 "github.com/GoogleCloudPlatform/k8s-config-connector/pkg/clients/generated/apis/container/v1beta1"
 	kmsv1beta1 "github.com/GoogleCloudPlatform/k8s-config-connector/pkg/clients/generated/apis/kms/v1beta1"
+	pubsubv1beta1 "github.com/GoogleCloudPlatform/k8s-config-connector/pkg/clients/generated/apis/pubsub/v1beta1"
 	redisv1beta1 "github.com/GoogleCloudPlatform/k8s-config-connector/pkg/clients/generated/apis/redis/v1beta1"
+	resourcemanagerv1beta1 "github.com/GoogleCloudPlatform/k8s-config-connector/pkg/clients/generated/apis/resourcemanager/v1beta1"
+	runv1beta1 "github.com/GoogleCloudPlatform/k8s-config-connector/pkg/clients/generated/apis/run/v1beta1"
+	secretmanagerv1beta1 "github.com/GoogleCloudPlatform/k8s-config-connector/pkg/clients/generated/apis/secretmanager/v1beta1"
 	sqlv1beta1 "github.com/GoogleCloudPlatform/k8s-config-connector/pkg/clients/generated/apis/sql/v1beta1"
 	storagev1beta1 "github.com/GoogleCloudPlatform/k8s-config-connector/pkg/clients/generated/apis/storage/v1beta1"
 	tagsv1alpha1 "github.com/GoogleCloudPlatform/k8s-config-connector/pkg/clients/generated/apis/tags/v1alpha1"
+	tagsv1beta1 "github.com/GoogleCloudPlatform/k8s-config-connector/pkg/clients/generated/apis/tags/v1beta1"
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
@@ -42,9 +52,12 @@ import (
 	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
 
+	taggingv1alpha1 "github.com/deliveryhero/gcp-config-connector-tagging-operator/api/v1alpha1"
 	"github.com/deliveryhero/gcp-config-connector-tagging-operator/internal/controller"
 	"github.com/deliveryhero/gcp-config-connector-tagging-operator/internal/controller/resources"
+	"github.com/deliveryhero/gcp-config-connector-tagging-operator/internal/dryrun"
 	"github.com/deliveryhero/gcp-config-connector-tagging-operator/internal/gcp"
+	"github.com/deliveryhero/gcp-config-connector-tagging-operator/internal/policy"
 	"github.com/deliveryhero/gcp-config-connector-tagging-operator/internal/util"
 	// +kubebuilder:scaffold:imports
 )
@@ -57,16 +70,24 @@ var (
 func init() {
 	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
 	utilruntime.Must(tagsv1alpha1.AddToScheme(scheme))
+	utilruntime.Must(tagsv1beta1.AddToScheme(scheme))
 	utilruntime.Must(storagev1beta1.AddToScheme(scheme))
 	utilruntime.Must(sqlv1beta1.AddToScheme(scheme))
 	utilruntime.Must(redisv1beta1.AddToScheme(scheme))
 	utilruntime.Must(kmsv1beta1.AddToScheme(scheme))
+	utilruntime.Must(pubsubv1beta1.AddToScheme(scheme))
+	utilruntime.Must(bigqueryv1beta1.AddToScheme(scheme))
+	utilruntime.Must(computev1beta1.AddToScheme(scheme))
+	utilruntime.Must(containerv1beta1.AddToScheme(scheme))
+	utilruntime.Must(runv1beta1.AddToScheme(scheme))
+	utilruntime.Must(artifactregistryv1beta1.AddToScheme(scheme))
+	utilruntime.Must(secretmanagerv1beta1.AddToScheme(scheme))
+	utilruntime.Must(resourcemanagerv1beta1.AddToScheme(scheme))
+	utilruntime.Must(taggingv1alpha1.AddToScheme(scheme))
 
 	// +kubebuilder:scaffold:scheme
 }
 
-// TODO add cronjob for cleaning unused tag values and keys
-
 func main() {
 	var metricsAddr string
 	var enableLeaderElection bool
@@ -74,6 +95,33 @@ func main() {
 	var secureMetrics bool
 	var enableHTTP2 bool
 	var targetLabels string
+	var tagParent string
+	var tagKeyParents string
+	var allowCreate bool
+	var clusterName string
+	var adoptExistingTags bool
+	var firewallTagKeys string
+	var firewallNetworkLabel string
+	var tagUser string
+	var tagUserFromContext bool
+	var tagCache string
+	var tagCacheSize int
+	var tagCacheTTL time.Duration
+	var tagCacheNegativeTTL time.Duration
+	var tagCacheRefreshInterval time.Duration
+	rateLimits := gcp.DefaultRateLimitConfig()
+	var gcInterval time.Duration
+	var gcGracePeriod time.Duration
+	var gcDryRun bool
+	var driftCheckInterval time.Duration
+	var repairDrift bool
+	var dryRun bool
+	var namespaceDefaultLabels string
+	var namespaceDefaultAnnotations string
+	var tagSources string
+	var tagKeyStripPrefix bool
+	var tagNameReplacement string
+	var tagNameLowercase bool
 	var tlsOpts []func(*tls.Config)
 	flag.StringVar(&metricsAddr, "metrics-bind-address", "0", "The address the metrics endpoint binds to. "+
 		"Use :8443 for HTTPS or :8080 for HTTP, or leave as 0 to disable the metrics service.")
@@ -86,8 +134,87 @@ func main() {
 	flag.BoolVar(&enableHTTP2, "enable-http2", false,
 		"If set, HTTP/2 will be enabled for the metrics and webhook servers")
 	flag.StringVar(&targetLabels, "target-labels", ".*",
-		"Only create tags for labels that match this regular expression. "+
+		"Only create tags for labels that match this regular expression, as long as no TaggingPolicy exists. "+
 			"Defaults to '.*', matching all labels by default.")
+	flag.StringVar(&namespaceDefaultLabels, "namespace-default-labels", "",
+		"Labels of a namespace matching this regular expression are default labels of all resources in it. "+
+			"Labels of the resources take precedence. Defaults to '', disabling namespace default labels.")
+	flag.BoolVar(&tagKeyStripPrefix, "tag-key-strip-prefix", false,
+		"Strip the prefix of label keys up to the last /, e.g. app.kubernetes.io/name becomes the tag key name.")
+	flag.StringVar(&tagNameReplacement, "tag-name-replacement", "",
+		"Replace characters tag keys and values must not contain (anything but letters, digits, dashes, underscores and dots) with this string. "+
+			"By default labels with such characters are reported as invalid.")
+	flag.BoolVar(&tagNameLowercase, "tag-name-lowercase", false,
+		"Convert tag keys and values to lower case.")
+	flag.StringVar(&tagSources, "tag-sources", string(policy.SourceLabels),
+		"Comma separated list of resource metadata tags are read from: 'labels' and 'annotations'. "+
+			"'annotations' reads '"+policy.TagAnnotationPrefix+"<key>' annotations and a JSON object in the '"+policy.TagsAnnotation+"' annotation. "+
+			"Later sources take precedence.")
+	flag.StringVar(&namespaceDefaultAnnotations, "namespace-default-annotations", "",
+		"Annotations of a namespace matching this regular expression are default labels of all resources in it. "+
+			"Labels of the resources and the namespace take precedence. Defaults to '', disabling namespace default annotations.")
+	flag.StringVar(&tagParent, "tag-parent", "",
+		"Create tag keys and values under this parent (organizations/<id> or projects/<project ID>) "+
+			"instead of the project of each resource. Tag bindings always target the resource's own project.")
+	flag.StringVar(&tagKeyParents, "tag-key-parents", "",
+		"Comma separated list of key=parent pairs overriding --tag-parent for individual tag keys, "+
+			"e.g. 'team=organizations/123456789,env=projects/tags-project'.")
+	flag.BoolVar(&allowCreate, "allow-create", true,
+		"If set, missing tag keys and values are created. Use --allow-create=false to only bind pre-existing ones.")
+	flag.StringVar(&clusterName, "cluster-name", "",
+		"Name of the cluster, noted in the description of the tag keys and values created by the operator.")
+	flag.BoolVar(&adoptExistingTags, "adopt-existing-tags", false,
+		"If set, pre-existing tag keys and values used by the operator are marked as created by it, "+
+			"so they are deleted once unused. By default only tags created by the operator are deleted.")
+	flag.StringVar(&firewallTagKeys, "firewall-tag-keys", "",
+		"Comma separated list of tag keys created with the GCE_FIREWALL purpose as secure tags for Cloud NGFW, "+
+			"each optionally followed by =network, e.g. 'fw-role=net-project/prod-vpc,fw-app'. "+
+			"Keys without network are created for the network in the --firewall-network-label label of the resource.")
+	flag.StringVar(&firewallNetworkLabel, "firewall-network-label", "",
+		"Label holding the name of the VPC network of a resource, in the project of the resource. "+
+			"Secure tags of other networks are not bound to the resource.")
+	flag.StringVar(&tagUser, "tag-user", "",
+		"Principal granted roles/resourcemanager.tagUser on the tag keys created by the operator, usually the service account "+
+			"of Config Connector, e.g. 'serviceAccount:cnrm-system@project.iam.gserviceaccount.com'. Defaults to '', granting no roles.")
+	flag.BoolVar(&tagUserFromContext, "tag-user-from-config-connector-context", false,
+		"If set, roles/resourcemanager.tagUser is granted to the service account in the ConfigConnectorContext of the namespace "+
+			"of each resource, for Config Connector in namespaced mode. Namespaces without one fall back to --tag-user.")
+	flag.StringVar(&tagCache, "tag-cache", gcp.CacheTypeMemory,
+		"Cache for tag keys, tag values and projects: 'memory' (unbounded), 'lru' (bounded by --tag-cache-size) or 'none'.")
+	flag.IntVar(&tagCacheSize, "tag-cache-size", 10000,
+		"Maximum number of entries of the 'lru' tag cache.")
+	flag.DurationVar(&tagCacheTTL, "tag-cache-ttl", gcp.DefaultCacheTTL,
+		"How long tag keys, tag values and projects are cached.")
+	flag.DurationVar(&tagCacheNegativeTTL, "tag-cache-negative-ttl", gcp.DefaultNegativeCacheTTL,
+		"How long missing tag keys and values are cached. 0 disables negative caching.")
+	flag.DurationVar(&tagCacheRefreshInterval, "tag-cache-refresh-interval", 0,
+		"Interval at which all tag keys matching --target-labels and their values are listed into the tag cache, "+
+			"starting at startup. 0 disables warming the cache.")
+	flag.Float64Var(&rateLimits.ReadQPS, "gcp-read-qps", gcp.DefaultReadQPS,
+		"Maximum rate of Resource Manager lookups per second. 0 disables the limit.")
+	flag.IntVar(&rateLimits.ReadBurst, "gcp-read-burst", gcp.DefaultReadBurst,
+		"Maximum burst of Resource Manager lookups.")
+	flag.Float64Var(&rateLimits.WriteQPS, "gcp-write-qps", gcp.DefaultWriteQPS,
+		"Maximum rate of Resource Manager creations and deletions per second. 0 disables the limit.")
+	flag.IntVar(&rateLimits.WriteBurst, "gcp-write-burst", gcp.DefaultWriteBurst,
+		"Maximum burst of Resource Manager creations and deletions.")
+	flag.IntVar(&rateLimits.MaxRetries, "gcp-max-retries", gcp.DefaultMaxRetries,
+		"How often Resource Manager calls failing with transient errors, like exhausted quotas, are retried. Writes are only retried on exhausted quotas.")
+	flag.DurationVar(&gcInterval, "gc-interval", 0,
+		"Interval at which unused tag values and keys created by the operator are deleted. "+
+			"Defaults to 0, disabling garbage collection.")
+	flag.DurationVar(&gcGracePeriod, "gc-grace-period", 24*time.Hour,
+		"Minimum age of a tag value or key before it is considered for garbage collection.")
+	flag.BoolVar(&gcDryRun, "gc-dry-run", false,
+		"If set, garbage collection only logs the tag values and keys it would delete.")
+	flag.DurationVar(&driftCheckInterval, "drift-detection-interval", 0,
+		"Interval at which the tag bindings of each resource are compared with the bindings in GCP. "+
+			"Defaults to 0, disabling drift detection.")
+	flag.BoolVar(&repairDrift, "drift-repair", false,
+		"If set, tag bindings missing in GCP are re-triggered for Config Connector reconciliation.")
+	flag.BoolVar(&dryRun, "dry-run", false,
+		"If set, no tags, tag bindings or other resources are created, changed or deleted. "+
+			"The intended actions are logged, reported as events and summarized at /dry-run on the metrics endpoint.")
 	opts := zap.Options{
 		Development: true,
 	}
@@ -145,6 +272,62 @@ func main() {
 		os.Exit(1)
 	}
 
+	sources, err := policy.ParseSources(tagSources)
+	if err != nil {
+		setupLog.Error(err, "unable to parse tag sources")
+		os.Exit(1)
+	}
+	normalizer, err := util.NewShortNameNormalizer(tagKeyStripPrefix, tagNameReplacement, tagNameLowercase)
+	if err != nil {
+		setupLog.Error(err, "invalid tag name normalization")
+		os.Exit(1)
+	}
+	evaluatorOpts := []policy.Option{policy.WithSources(sources...), policy.WithNormalizer(normalizer)}
+	if namespaceDefaultLabels != "" {
+		matcher, err := util.LimitLabelsWithRegex(namespaceDefaultLabels)
+		if err != nil {
+			setupLog.Error(err, "unable to compile regex for namespace default labels")
+			os.Exit(1)
+		}
+		evaluatorOpts = append(evaluatorOpts, policy.WithNamespaceLabels(matcher))
+	}
+	if namespaceDefaultAnnotations != "" {
+		matcher, err := util.LimitLabelsWithRegex(namespaceDefaultAnnotations)
+		if err != nil {
+			setupLog.Error(err, "unable to compile regex for namespace default annotations")
+			os.Exit(1)
+		}
+		evaluatorOpts = append(evaluatorOpts, policy.WithNamespaceAnnotations(matcher))
+	}
+
+	var tagsManagerOpts []gcp.Option
+	if tagParent != "" {
+		if err := gcp.ValidateTagParent(tagParent); err != nil {
+			setupLog.Error(err, "invalid tag parent")
+			os.Exit(1)
+		}
+		tagsManagerOpts = append(tagsManagerOpts, gcp.WithTagParent(tagParent))
+	}
+	keyParents, err := gcp.ParseKeyParents(tagKeyParents)
+	if err != nil {
+		setupLog.Error(err, "invalid tag key parents")
+		os.Exit(1)
+	}
+	tagsManagerOpts = append(tagsManagerOpts, gcp.WithKeyParents(keyParents), gcp.WithAllowCreate(allowCreate),
+		gcp.WithClusterName(clusterName), gcp.WithAdoptExisting(adoptExistingTags))
+	firewallKeys, err := gcp.ParseFirewallKeys(firewallTagKeys)
+	if err != nil {
+		setupLog.Error(err, "invalid firewall tag keys")
+		os.Exit(1)
+	}
+	tagsManagerOpts = append(tagsManagerOpts, gcp.WithFirewallKeys(firewallKeys), gcp.WithTagUser(tagUser))
+	cache, err := gcp.NewCache(tagCache, tagCacheSize)
+	if err != nil {
+		setupLog.Error(err, "invalid tag cache")
+		os.Exit(1)
+	}
+	tagsManagerOpts = append(tagsManagerOpts, gcp.WithCache(cache), gcp.WithCacheTTL(tagCacheTTL), gcp.WithNegativeCacheTTL(tagCacheNegativeTTL))
+
 	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
 		Scheme:                 scheme,
 		Metrics:                metricsServerOptions,
@@ -170,36 +353,108 @@ func main() {
 	}
 
 	ctx := context.Background()
-	tagKeysClient, err := resourcemanager.NewTagKeysClient(ctx)
+	rateLimiter := gcp.NewRateLimiter(rateLimits)
+	tagKeysClient, err := resourcemanager.NewTagKeysClient(ctx, rateLimiter.ClientOption())
 	if err != nil {
 		setupLog.Error(err, "unable to create tag keys client")
 		os.Exit(1)
 	}
-	tagValuesClient, err := resourcemanager.NewTagValuesClient(ctx)
+	tagValuesClient, err := resourcemanager.NewTagValuesClient(ctx, rateLimiter.ClientOption())
 	if err != nil {
 		setupLog.Error(err, "unable to create tag values client")
 		os.Exit(1)
 	}
 
-	projectClient, err := resourcemanager.NewProjectsClient(ctx)
+	projectClient, err := resourcemanager.NewProjectsClient(ctx, rateLimiter.ClientOption())
 	if err != nil {
 		setupLog.Error(err, "unable to create project client")
 		os.Exit(1)
 	}
 
-	tagsManager := gcp.NewTagsManager(tagKeysClient, tagValuesClient, projectClient)
+	// calls are retried by the rate limiter, retrying them in the clients as well would multiply the attempts
+	gcp.DisableClientRetries(tagKeysClient.CallOptions)
+	gcp.DisableClientRetries(tagValuesClient.CallOptions)
+	gcp.DisableClientRetries(projectClient.CallOptions)
+	tagsManagerOpts = append(tagsManagerOpts, gcp.WithTagBindingsClientFactory(rateLimiter.TagBindingsClientFactory()))
+
+	var dryRunRecorder *dryrun.Recorder
+	if dryRun {
+		setupLog.Info("running in dry-run mode, no changes will be made")
+		dryRunRecorder = dryrun.NewRecorder()
+		tagsManagerOpts = append(tagsManagerOpts, gcp.WithDryRun(dryRunRecorder))
+		if err := mgr.AddMetricsServerExtraHandler("/dry-run", dryRunRecorder); err != nil {
+			setupLog.Error(err, "unable to set up dry-run summary endpoint")
+			os.Exit(1)
+		}
+	}
+	tagsManager := gcp.NewTagsManager(tagKeysClient, tagValuesClient, projectClient, tagsManagerOpts...)
 
 	err = controller.SetupTagBindingIndex(mgr)
 	if err != nil {
 		setupLog.Error(err, "unable to setup tag binding index")
 		os.Exit(1)
 	}
-	controller.CreateTaggableResourceController(mgr, tagsManager, &resources.StorageBucketMetadataProvider{}, labelMatcher)
-	controller.CreateTaggableResourceController(mgr, tagsManager, &resources.SQLInstanceMetadataProvider{}, labelMatcher)
-	controller.CreateTaggableResourceController(mgr, tagsManager, &resources.RedisInstanceMetadataProvider{}, labelMatcher)
-	controller.CreateTaggableResourceController(mgr, tagsManager, &resources.KMSKeyRingMetadataProvider{}, labelMatcher)
+	tagEvaluator := policy.NewEvaluator(mgr.GetClient(), labelMatcher, evaluatorOpts...)
+	reconcilerOpts := controller.ReconcilerOptions{
+		DriftCheckInterval:   driftCheckInterval,
+		RepairDrift:          repairDrift,
+		DryRun:               dryRunRecorder,
+		FirewallNetworkLabel: firewallNetworkLabel,
+		TagUserFromContext:   tagUserFromContext,
+	}
+	controller.CreateTaggableResourceController(mgr, tagsManager, &resources.StorageBucketMetadataProvider{}, tagEvaluator, reconcilerOpts)
+	controller.CreateTaggableResourceController(mgr, tagsManager, &resources.SQLInstanceMetadataProvider{}, tagEvaluator, reconcilerOpts)
+	controller.CreateTaggableResourceController(mgr, tagsManager, &resources.RedisInstanceMetadataProvider{}, tagEvaluator, reconcilerOpts)
+	controller.CreateTaggableResourceController(mgr, tagsManager, &resources.KMSKeyRingMetadataProvider{}, tagEvaluator, reconcilerOpts)
+	controller.CreateTaggableResourceController(mgr, tagsManager, &resources.PubSubTopicMetadataProvider{}, tagEvaluator, reconcilerOpts)
+	controller.CreateTaggableResourceController(mgr, tagsManager, &resources.PubSubSubscriptionMetadataProvider{}, tagEvaluator, reconcilerOpts)
+	controller.CreateTaggableResourceController(mgr, tagsManager, &resources.BigQueryDatasetMetadataProvider{}, tagEvaluator, reconcilerOpts)
+	controller.CreateTaggableResourceController(mgr, tagsManager, &resources.BigQueryTableMetadataProvider{}, tagEvaluator, reconcilerOpts)
+	controller.CreateTaggableResourceController(mgr, tagsManager, &resources.ComputeInstanceMetadataProvider{}, tagEvaluator, reconcilerOpts)
+	controller.CreateTaggableResourceController(mgr, tagsManager, &resources.ComputeDiskMetadataProvider{}, tagEvaluator, reconcilerOpts)
+	controller.CreateTaggableResourceController(mgr, tagsManager, &resources.ContainerClusterMetadataProvider{}, tagEvaluator, reconcilerOpts)
+	controller.CreateTaggableResourceController(mgr, tagsManager, &resources.ContainerNodePoolMetadataProvider{}, tagEvaluator, reconcilerOpts)
+	controller.CreateTaggableResourceController(mgr, tagsManager, &resources.RunServiceMetadataProvider{}, tagEvaluator, reconcilerOpts)
+	controller.CreateTaggableResourceController(mgr, tagsManager, &resources.ArtifactRegistryRepositoryMetadataProvider{}, tagEvaluator, reconcilerOpts)
+	controller.CreateTaggableResourceController(mgr, tagsManager, &resources.SecretManagerSecretMetadataProvider{}, tagEvaluator, reconcilerOpts)
+	if err := (&controller.TaggingPolicyReconciler{
+		Client: mgr.GetClient(),
+		Scheme: mgr.GetScheme(),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "TaggingPolicy")
+		os.Exit(1)
+	}
 	// +kubebuilder:scaffold:builder
 
+	if gcInterval > 0 {
+		if err := mgr.Add(&controller.TagGarbageCollector{
+			Client:       mgr.GetClient(),
+			TagsManager:  tagsManager,
+			TagEvaluator: tagEvaluator,
+			Interval:     gcInterval,
+			GracePeriod:  gcGracePeriod,
+			DryRun:       gcDryRun || dryRun,
+		}); err != nil {
+			setupLog.Error(err, "unable to set up tag garbage collector")
+			os.Exit(1)
+		}
+	}
+
+	if tagCacheRefreshInterval > 0 {
+		if tagCacheRefreshInterval > tagCacheTTL {
+			setupLog.Info("tag cache entries expire before they are refreshed, consider a --tag-cache-refresh-interval below --tag-cache-ttl")
+		}
+		if err := mgr.Add(&controller.TagCacheWarmer{
+			Client:       mgr.GetClient(),
+			TagsManager:  tagsManager,
+			TagEvaluator: tagEvaluator,
+			Interval:     tagCacheRefreshInterval,
+		}); err != nil {
+			setupLog.Error(err, "unable to set up tag cache warmer")
+			os.Exit(1)
+		}
+	}
+
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
 		setupLog.Error(err, "unable to set up health check")
 		os.Exit(1)
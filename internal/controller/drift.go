@@ -0,0 +1,124 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"cloud.google.com/go/resourcemanager/apiv3/resourcemanagerpb"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	driftDetectedAnnotation = "gdp.deliveryhero.io/drift-detected-at"
+
+	driftTypeMissing     = "missing"
+	driftTypeConflicting = "conflicting"
+)
+
+// detectDrift compares the expected tag values with the bindings actually present in GCP.
+// Only bindings Config Connector reports as ready are checked, everything else is still in progress.
+func (r *TaggableResourceReconciler[T, P, PT]) detectDrift(ctx context.Context, resource PT, parent string, expectedValues []*resourcemanagerpb.TagValue, boundTags map[string]client.Object) error {
+	log := log.FromContext(ctx)
+
+	location := r.MetadataProvider.GetResourceLocation(resource)
+	actualBindings, err := r.TagsManager.ListBindings(ctx, location, parent)
+	if err != nil {
+		r.recordGCPError(resource, err)
+		return fmt.Errorf("failed to list tag bindings in GCP: %w", err)
+	}
+
+	actualValues := make(map[string]bool, len(actualBindings))
+	actualValuesByKey := make(map[string][]string, len(actualBindings))
+	for _, binding := range actualBindings {
+		actualValues[binding.TagValue] = true
+		key := namespacedTagKey(binding.TagValueNamespacedName)
+		actualValuesByKey[key] = append(actualValuesByKey[key], binding.TagValueNamespacedName)
+	}
+
+	kind := resource.GetObjectKind().GroupVersionKind().Kind
+	for _, value := range expectedValues {
+		binding, exists := boundTags[tagBindingResourceName(resource, value.Name)]
+		if !exists || !tagBindingReady(binding) {
+			continue
+		}
+
+		if !actualValues[value.Name] {
+			log.Info("tag binding missing in GCP", "tagValue", value.NamespacedName, "tagBinding", binding.GetName())
+			tagBindingDriftTotal.WithLabelValues(kind, driftTypeMissing).Inc()
+			r.Recorder.Eventf(resource, corev1.EventTypeWarning, EventReasonTagBindingMissing,
+				"Tag value %s is bound by %s but not in GCP", value.NamespacedName, binding.GetName())
+			// the tag value may have been deleted, so it must be looked up again instead of served from the cache
+			r.TagsManager.Invalidate(value.Name)
+			if r.RepairDrift {
+				if err := r.retriggerTagBinding(ctx, binding); err != nil {
+					return err
+				}
+			}
+		}
+
+		for _, actual := range actualValuesByKey[namespacedTagKey(value.NamespacedName)] {
+			if actual == value.NamespacedName {
+				continue
+			}
+			log.Info("conflicting tag value bound in GCP", "tagValue", value.NamespacedName, "conflictingTagValue", actual)
+			tagBindingDriftTotal.WithLabelValues(kind, driftTypeConflicting).Inc()
+			r.Recorder.Eventf(resource, corev1.EventTypeWarning, EventReasonTagBindingConflict,
+				"Tag value %s is bound in GCP instead of %s", actual, value.NamespacedName)
+		}
+	}
+
+	return nil
+}
+
+// retriggerTagBinding touches the binding so Config Connector reconciles it again and recreates it in GCP.
+func (r *TaggableResourceReconciler[T, P, PT]) retriggerTagBinding(ctx context.Context, binding client.Object) error {
+	patch := client.MergeFrom(binding.DeepCopyObject().(client.Object))
+	annotations := binding.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[driftDetectedAnnotation] = time.Now().UTC().Format(time.RFC3339)
+	binding.SetAnnotations(annotations)
+	if err := r.Patch(ctx, binding, patch); err != nil {
+		return fmt.Errorf("failed to re-trigger tag binding %s: %w", binding.GetName(), err)
+	}
+	return nil
+}
+
+func tagBindingReady(binding client.Object) bool {
+	for _, condition := range tagBindingConditions(binding) {
+		if condition.Type == "Ready" {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// namespacedTagKey returns the namespaced name of the tag key of a namespaced tag value,
+// e.g. "my-project/env" for "my-project/env/prod".
+func namespacedTagKey(namespacedValue string) string {
+	if i := strings.LastIndex(namespacedValue, "/"); i >= 0 {
+		return namespacedValue[:i]
+	}
+	return namespacedValue
+}
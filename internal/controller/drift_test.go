@@ -0,0 +1,163 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"cloud.google.com/go/resourcemanager/apiv3/resourcemanagerpb"
+	ccv1alpha1 "github.com/GoogleCloudPlatform/k8s-config-connector/pkg/clients/generated/apis/k8s/v1alpha1"
+	storagev1beta1 "github.com/GoogleCloudPlatform/k8s-config-connector/pkg/clients/generated/apis/storage/v1beta1"
+	tagsv1alpha1 "github.com/GoogleCloudPlatform/k8s-config-connector/pkg/clients/generated/apis/tags/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("Drift detection", func() {
+	var (
+		ctx         context.Context
+		bucket      *storagev1beta1.StorageBucket
+		binding     *tagsv1alpha1.TagsLocationTagBinding
+		tagsManager *fakeTagsManager
+		recorder    *record.FakeRecorder
+		reconciler  *TaggableResourceReconciler[storagev1beta1.StorageBucket, *testBucketMetadataProvider, *storagev1beta1.StorageBucket]
+	)
+
+	expectedValue := &resourcemanagerpb.TagValue{Name: "tagValues/11", NamespacedName: "test-project/team/payments"}
+	const bucketResourceID = "//storage.googleapis.com/projects/_/buckets/test-bucket"
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		bucket = &storagev1beta1.StorageBucket{
+			TypeMeta:   metav1.TypeMeta{Kind: "StorageBucket", APIVersion: "storage.cnrm.cloud.google.com/v1beta1"},
+			ObjectMeta: metav1.ObjectMeta{Name: "test-bucket", Namespace: "default"},
+			Spec:       storagev1beta1.StorageBucketSpec{Location: ptr.To("EU")},
+		}
+		binding = &tagsv1alpha1.TagsLocationTagBinding{
+			ObjectMeta: metav1.ObjectMeta{Name: tagBindingResourceName(bucket, expectedValue.Name), Namespace: "default"},
+			Spec: tagsv1alpha1.TagsLocationTagBindingSpec{
+				TagValueRef: ccv1alpha1.ResourceRef{External: expectedValue.Name},
+			},
+			Status: tagsv1alpha1.TagsLocationTagBindingStatus{
+				Conditions: []ccv1alpha1.Condition{{Type: "Ready", Status: corev1.ConditionTrue}},
+			},
+		}
+
+		scheme := runtime.NewScheme()
+		Expect(tagsv1alpha1.AddToScheme(scheme)).To(Succeed())
+		Expect(storagev1beta1.AddToScheme(scheme)).To(Succeed())
+
+		tagsManager = &fakeTagsManager{}
+		recorder = record.NewFakeRecorder(10)
+		reconciler = &TaggableResourceReconciler[storagev1beta1.StorageBucket, *testBucketMetadataProvider, *storagev1beta1.StorageBucket]{
+			Client:           fake.NewClientBuilder().WithScheme(scheme).WithObjects(binding).Build(),
+			Scheme:           scheme,
+			TagsManager:      tagsManager,
+			MetadataProvider: &testBucketMetadataProvider{},
+			Recorder:         recorder,
+		}
+	})
+
+	It("should not report anything when GCP matches", func() {
+		tagsManager.bindings = []*resourcemanagerpb.TagBinding{
+			{TagValue: expectedValue.Name, TagValueNamespacedName: expectedValue.NamespacedName},
+		}
+		err := reconciler.detectDrift(ctx, bucket, bucketResourceID, []*resourcemanagerpb.TagValue{expectedValue},
+			map[string]client.Object{binding.Name: binding})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(recorder.Events).To(BeEmpty())
+		Expect(tagsManager.listedBindingsLocation).To(Equal("EU"))
+		Expect(tagsManager.listedBindingsParent).To(Equal(bucketResourceID))
+		Expect(tagsManager.invalidated).To(BeEmpty())
+	})
+
+	It("should report and repair missing bindings", func() {
+		reconciler.RepairDrift = true
+		err := reconciler.detectDrift(ctx, bucket, bucketResourceID, []*resourcemanagerpb.TagValue{expectedValue},
+			map[string]client.Object{binding.Name: binding})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(recorder.Events).To(Receive(ContainSubstring("TagBindingMissing")))
+		Expect(tagsManager.invalidated).To(ConsistOf(expectedValue.Name))
+
+		var updated tagsv1alpha1.TagsLocationTagBinding
+		Expect(reconciler.Get(ctx, client.ObjectKeyFromObject(binding), &updated)).To(Succeed())
+		Expect(updated.Annotations).To(HaveKey(driftDetectedAnnotation))
+	})
+
+	It("should report conflicting values of the same key", func() {
+		tagsManager.bindings = []*resourcemanagerpb.TagBinding{
+			{TagValue: expectedValue.Name, TagValueNamespacedName: expectedValue.NamespacedName},
+			{TagValue: "tagValues/12", TagValueNamespacedName: "test-project/team/search"},
+			{TagValue: "tagValues/99", TagValueNamespacedName: "test-project/other/value"},
+		}
+		err := reconciler.detectDrift(ctx, bucket, bucketResourceID, []*resourcemanagerpb.TagValue{expectedValue},
+			map[string]client.Object{binding.Name: binding})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(recorder.Events).To(HaveLen(1))
+		Expect(recorder.Events).To(Receive(ContainSubstring("TagBindingConflict")))
+	})
+
+	It("should skip bindings which are not ready yet", func() {
+		binding.Status.Conditions = nil
+		err := reconciler.detectDrift(ctx, bucket, bucketResourceID, []*resourcemanagerpb.TagValue{expectedValue},
+			map[string]client.Object{binding.Name: binding})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(recorder.Events).To(BeEmpty())
+	})
+
+	Describe("NamespacedTagKey function", func() {
+		It("should strip the value short name", func() {
+			Expect(namespacedTagKey("test-project/team/payments")).To(Equal("test-project/team"))
+		})
+	})
+})
+
+// testBucketMetadataProvider mirrors resources.StorageBucketMetadataProvider, which cannot be imported here.
+type testBucketMetadataProvider struct {
+	firewallTags bool
+	// location overrides the location of the bucket
+	location string
+	// statusPending makes the bucket wait for its status like resources implementing ResourceStatusProvider
+	statusPending bool
+}
+
+func (in *testBucketMetadataProvider) GetResourceLocation(r *storagev1beta1.StorageBucket) string {
+	if in.location != "" {
+		return in.location
+	}
+	return ptr.Deref(r.Spec.Location, "")
+}
+
+func (in *testBucketMetadataProvider) SupportsFirewallTags() bool {
+	return in.firewallTags
+}
+
+func (in *testBucketMetadataProvider) HasResourceStatus(_ *storagev1beta1.StorageBucket) bool {
+	return !in.statusPending
+}
+
+func (in *testBucketMetadataProvider) GetResourceID(_ *resourcemanagerpb.Project, r *storagev1beta1.StorageBucket) string {
+	return "//storage.googleapis.com/projects/_/buckets/" + r.Name
+}
@@ -0,0 +1,66 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"cloud.google.com/go/resourcemanager/apiv3/resourcemanagerpb"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+)
+
+// Event reasons emitted on the tagged resources. They are part of the operator's interface,
+// so alerts can rely on them; do not rename them.
+const (
+	EventReasonTagBindingCreated         = "TagBindingCreated"
+	EventReasonTagBindingReplaced        = "TagBindingReplaced"
+	EventReasonTagBindingRemoved         = "TagBindingRemoved"
+	EventReasonTagBindingFailed          = "TagBindingFailed"
+	EventReasonTagBindingMissing         = "TagBindingMissing"
+	EventReasonTagBindingConflict        = "TagBindingConflict"
+	EventReasonTagKeyCreated             = "TagKeyCreated"
+	EventReasonTagValueCreated           = "TagValueCreated"
+	EventReasonProjectResolutionFallback = "ProjectResolutionFallback"
+	EventReasonRequiredLabelMissing      = "RequiredLabelMissing"
+	EventReasonInvalidTag                = "InvalidTag"
+	EventReasonGCPError                  = "GCPError"
+)
+
+// creationEventRecorder emits events on a resource for tag keys and values created on its behalf.
+type creationEventRecorder struct {
+	recorder record.EventRecorder
+	object   runtime.Object
+}
+
+func (r *creationEventRecorder) TagKeyCreated(key *resourcemanagerpb.TagKey) {
+	r.recorder.Eventf(r.object, corev1.EventTypeNormal, EventReasonTagKeyCreated,
+		"Created tag key %s (%s)", key.NamespacedName, key.Name)
+}
+
+func (r *creationEventRecorder) TagValueCreated(value *resourcemanagerpb.TagValue) {
+	r.recorder.Eventf(r.object, corev1.EventTypeNormal, EventReasonTagValueCreated,
+		"Created tag value %s (%s)", value.NamespacedName, value.Name)
+}
+
+func (r *TaggableResourceReconciler[T, P, PT]) recordTagBindingFailure(resource PT, bindingName string, err error) {
+	r.Recorder.Eventf(resource, corev1.EventTypeWarning, EventReasonTagBindingFailed,
+		"Failed to update tag binding %s: %v", bindingName, err)
+}
+
+func (r *TaggableResourceReconciler[T, P, PT]) recordGCPError(resource PT, err error) {
+	r.Recorder.Eventf(resource, corev1.EventTypeWarning, EventReasonGCPError, "GCP request failed: %v", err)
+}
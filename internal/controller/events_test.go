@@ -0,0 +1,204 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"cloud.google.com/go/resourcemanager/apiv3/resourcemanagerpb"
+	storagev1beta1 "github.com/GoogleCloudPlatform/k8s-config-connector/pkg/clients/generated/apis/storage/v1beta1"
+	tagsv1alpha1 "github.com/GoogleCloudPlatform/k8s-config-connector/pkg/clients/generated/apis/tags/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	taggingv1alpha1 "github.com/deliveryhero/gcp-config-connector-tagging-operator/api/v1alpha1"
+	"github.com/deliveryhero/gcp-config-connector-tagging-operator/internal/dryrun"
+)
+
+// dryRunStatusNotFoundClient answers dry-run status updates of objects which do not exist with NotFound like the
+// API server, while the fake client accepts them.
+type dryRunStatusNotFoundClient struct {
+	client.Client
+}
+
+func (c *dryRunStatusNotFoundClient) Status() client.SubResourceWriter {
+	return c.SubResource("status")
+}
+
+func (c *dryRunStatusNotFoundClient) SubResource(subResource string) client.SubResourceClient {
+	return &dryRunStatusNotFoundSubResourceClient{SubResourceClient: c.Client.SubResource(subResource), client: c.Client}
+}
+
+type dryRunStatusNotFoundSubResourceClient struct {
+	client.SubResourceClient
+	client client.Client
+}
+
+func (c *dryRunStatusNotFoundSubResourceClient) Update(ctx context.Context, obj client.Object, opts ...client.SubResourceUpdateOption) error {
+	existing := obj.DeepCopyObject().(client.Object)
+	if err := c.client.Get(ctx, client.ObjectKeyFromObject(obj), existing); err != nil {
+		return err
+	}
+	return c.SubResourceClient.Update(ctx, obj, opts...)
+}
+
+var _ = Describe("Events", func() {
+	var (
+		ctx         context.Context
+		tagsManager *fakeTagsManager
+		bucket      *storagev1beta1.StorageBucket
+		request     ctrl.Request
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		tagsManager = &fakeTagsManager{}
+		bucket = &storagev1beta1.StorageBucket{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "test-bucket",
+				Namespace:   "default",
+				Labels:      map[string]string{"team": "payments"},
+				Annotations: map[string]string{projectIDAnnotation: "test-project"},
+			},
+		}
+		request = ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "test-bucket"}}
+	})
+
+	events := func(reconciler *testBucketReconciler) []string {
+		recorder := reconciler.Recorder.(*record.FakeRecorder)
+		var result []string
+		for len(recorder.Events) > 0 {
+			result = append(result, <-recorder.Events)
+		}
+		return result
+	}
+
+	It("should report created and removed tag bindings", func() {
+		reconciler := newTestBucketReconciler(tagsManager, bucket)
+		_, err := reconciler.Reconcile(ctx, request)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(events(reconciler)).To(ConsistOf(
+			"Normal TagBindingCreated Created tag binding storagebucket-test-bucket-team-payments for tag value test-project/team/payments",
+		))
+
+		var updated storagev1beta1.StorageBucket
+		Expect(reconciler.Get(ctx, request.NamespacedName, &updated)).To(Succeed())
+		updated.Labels = nil
+		Expect(reconciler.Update(ctx, &updated)).To(Succeed())
+
+		_, err = reconciler.Reconcile(ctx, request)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(events(reconciler)).To(ConsistOf(
+			"Normal TagBindingRemoved Removed tag binding storagebucket-test-bucket-team-payments",
+		))
+	})
+
+	It("should report GCP errors", func() {
+		tagsManager.lookupErr = fmt.Errorf("permission denied")
+		reconciler := newTestBucketReconciler(tagsManager, bucket)
+		_, err := reconciler.Reconcile(ctx, request)
+		Expect(err).To(HaveOccurred())
+		Expect(events(reconciler)).To(ConsistOf("Warning GCPError GCP request failed: permission denied"))
+	})
+
+	It("should report falling back to the namespace name as project", func() {
+		bucket.Annotations = nil
+		reconciler := newTestBucketReconciler(tagsManager, bucket)
+		_, err := reconciler.Reconcile(ctx, request)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(events(reconciler)).To(ContainElement(HavePrefix("Warning ProjectResolutionFallback")))
+	})
+
+	It("should report falling back to the namespace name only once", func() {
+		bucket.Annotations = nil
+		reconciler := newTestBucketReconciler(tagsManager, bucket)
+		_, err := reconciler.Reconcile(ctx, request)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(events(reconciler)).To(ContainElement(HavePrefix("Warning ProjectResolutionFallback")))
+
+		_, err = reconciler.Reconcile(ctx, request)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(events(reconciler)).NotTo(ContainElement(HavePrefix("Warning ProjectResolutionFallback")))
+	})
+
+	It("should report falling back to the namespace name only once in dry-run mode", func() {
+		bucket.Annotations = nil
+		reconciler := newTestBucketReconciler(tagsManager, bucket)
+		recorder := dryrun.NewRecorder()
+		reconciler.DryRun = recorder
+		reconciler.Client = dryrun.NewClient(&dryRunStatusNotFoundClient{Client: reconciler.Client}, recorder)
+
+		_, err := reconciler.Reconcile(ctx, request)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(events(reconciler)).To(ContainElement(HavePrefix("Warning ProjectResolutionFallback")))
+
+		// the TagAssignment is never persisted, which must not repeat the warning
+		_, err = reconciler.Reconcile(ctx, request)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(events(reconciler)).NotTo(ContainElement(HavePrefix("Warning ProjectResolutionFallback")))
+	})
+
+	It("should only record changes in dry-run mode", func() {
+		reconciler := newTestBucketReconciler(tagsManager, bucket)
+		recorder := dryrun.NewRecorder()
+		reconciler.Client = dryrun.NewClient(reconciler.Client, recorder)
+		fakeRecorder := reconciler.Recorder
+		reconciler.Recorder = dryrun.NewEventRecorder(fakeRecorder)
+
+		_, err := reconciler.Reconcile(ctx, request)
+		Expect(err).NotTo(HaveOccurred())
+		reconciler.Recorder = fakeRecorder
+		Expect(events(reconciler)).To(ConsistOf(HavePrefix("Normal TagBindingCreated [dry-run] Created tag binding")))
+
+		var bindings tagsv1alpha1.TagsLocationTagBindingList
+		Expect(reconciler.List(ctx, &bindings)).To(Succeed())
+		Expect(bindings.Items).To(BeEmpty())
+		Expect(recorder.Summary().Totals).To(HaveKeyWithValue("create TagsLocationTagBinding", 1))
+	})
+
+	It("should not fail on the status of a TagAssignment only created in dry-run mode", func() {
+		reconciler := newTestBucketReconciler(tagsManager, bucket)
+		recorder := dryrun.NewRecorder()
+		reconciler.DryRun = recorder
+		reconciler.Client = dryrun.NewClient(&dryRunStatusNotFoundClient{Client: reconciler.Client}, recorder)
+
+		_, err := reconciler.Reconcile(ctx, request)
+		Expect(err).NotTo(HaveOccurred())
+
+		var assignments taggingv1alpha1.TagAssignmentList
+		Expect(reconciler.List(ctx, &assignments)).To(Succeed())
+		Expect(assignments.Items).To(BeEmpty())
+		Expect(recorder.Summary().Totals).To(HaveKeyWithValue("create TagAssignment", 1))
+		Expect(recorder.Summary().Totals).To(HaveKeyWithValue("update/status TagAssignment", 1))
+	})
+
+	It("should report tag keys and values created on behalf of a resource", func() {
+		recorder := record.NewFakeRecorder(10)
+		notifier := &creationEventRecorder{recorder: recorder, object: bucket}
+		notifier.TagKeyCreated(&resourcemanagerpb.TagKey{Name: "tagKeys/1", NamespacedName: "test-project/team"})
+		notifier.TagValueCreated(&resourcemanagerpb.TagValue{Name: "tagValues/2", NamespacedName: "test-project/team/payments"})
+		Expect(recorder.Events).To(Receive(Equal("Normal TagKeyCreated Created tag key test-project/team (tagKeys/1)")))
+		Expect(recorder.Events).To(Receive(Equal("Normal TagValueCreated Created tag value test-project/team/payments (tagValues/2)")))
+	})
+})
@@ -0,0 +1,151 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	"cloud.google.com/go/resourcemanager/apiv3/resourcemanagerpb"
+
+	"github.com/deliveryhero/gcp-config-connector-tagging-operator/internal/gcp"
+)
+
+// fakeTagsManager serves keys and values from memory and records deletions.
+type fakeTagsManager struct {
+	gcp.TagsManager
+	lookupErr     error
+	missingValues map[string]bool
+	ensured       int
+	// pendingValues maps "key/value" to the name of an operation creating the value, until it is finished
+	pendingValues      map[string]string
+	finishedOperations map[string]bool
+	checkedOperations  []string
+	keys               map[string][]*resourcemanagerpb.TagKey
+	values             map[string][]*resourcemanagerpb.TagValue
+	bindings           []*resourcemanagerpb.TagBinding
+	deletedKeys        []string
+	deletedValues      []string
+	// boundValues are still bound to resources in GCP and cannot be deleted
+	boundValues   map[string]bool
+	invalidated   []string
+	warmedParents []string
+	warmedKeys    []string
+	// firewallNetworks maps the keys of secure tags to their network
+	firewallNetworks map[string]string
+
+	listedBindingsLocation string
+	listedBindingsParent   string
+}
+
+func (m *fakeTagsManager) EnsureValue(_ context.Context, projectID string, key string, value string) (*resourcemanagerpb.TagValue, error) {
+	m.ensured++
+	if m.lookupErr != nil {
+		return nil, m.lookupErr
+	}
+	if op, pending := m.pendingValues[key+"/"+value]; pending && !m.finishedOperations[op] {
+		return nil, &gcp.OperationPendingError{Kind: gcp.KindTagValue, Name: fmt.Sprintf("%s/%s/%s", projectID, key, value), Operation: op}
+	}
+	return &resourcemanagerpb.TagValue{
+		Name:           fmt.Sprintf("tagValues/%s-%s", key, value),
+		NamespacedName: fmt.Sprintf("%s/%s/%s", projectID, key, value),
+		ShortName:      value,
+	}, nil
+}
+
+// GetValue reports values listed in missingValues as "key/value" as not found.
+func (m *fakeTagsManager) GetValue(_ context.Context, projectID string, key string, value string) (*resourcemanagerpb.TagValue, error) {
+	if m.missingValues[key+"/"+value] {
+		return nil, fmt.Errorf("tag value %s/%s/%s %w", projectID, key, value, gcp.ErrNotFound)
+	}
+	return &resourcemanagerpb.TagValue{
+		Name:           fmt.Sprintf("tagValues/%s-%s", key, value),
+		NamespacedName: fmt.Sprintf("%s/%s/%s", projectID, key, value),
+		ShortName:      value,
+	}, nil
+}
+
+func (m *fakeTagsManager) GetKey(_ context.Context, projectID string, key string) (*resourcemanagerpb.TagKey, error) {
+	return &resourcemanagerpb.TagKey{
+		Name:           "tagKeys/" + key,
+		NamespacedName: fmt.Sprintf("%s/%s", projectID, key),
+		ShortName:      key,
+	}, nil
+}
+
+func (m *fakeTagsManager) FirewallNetwork(_ context.Context, _ string, key string) (string, bool, error) {
+	network, firewall := m.firewallNetworks[key]
+	return network, firewall, nil
+}
+
+func (m *fakeTagsManager) GetProjectInfo(_ context.Context, projectID string) (*resourcemanagerpb.Project, error) {
+	return &resourcemanagerpb.Project{Name: "projects/123456", ProjectId: projectID}, nil
+}
+
+func (m *fakeTagsManager) TagParents(projectIDs ...string) []string {
+	var parents []string
+	for _, projectID := range projectIDs {
+		parents = append(parents, "projects/"+projectID)
+	}
+	return parents
+}
+
+func (m *fakeTagsManager) ListKeys(_ context.Context, parent string) ([]*resourcemanagerpb.TagKey, error) {
+	return m.keys[parent], nil
+}
+
+func (m *fakeTagsManager) ListValues(_ context.Context, key string) ([]*resourcemanagerpb.TagValue, error) {
+	return m.values[key], nil
+}
+
+func (m *fakeTagsManager) DeleteValueIfUnused(_ context.Context, _ string, _ string, value string) (bool, error) {
+	if m.boundValues[value] {
+		return false, nil
+	}
+	m.deletedValues = append(m.deletedValues, value)
+	return true, nil
+}
+
+func (m *fakeTagsManager) DeleteKeyIfUnused(_ context.Context, _ string, key string) error {
+	m.deletedKeys = append(m.deletedKeys, key)
+	return nil
+}
+
+func (m *fakeTagsManager) ListBindings(_ context.Context, location string, parent string) ([]*resourcemanagerpb.TagBinding, error) {
+	m.listedBindingsLocation = location
+	m.listedBindingsParent = parent
+	return m.bindings, nil
+}
+
+func (m *fakeTagsManager) Invalidate(name string) {
+	m.invalidated = append(m.invalidated, name)
+}
+
+func (m *fakeTagsManager) CheckOperation(_ context.Context, _ string, name string) (bool, error) {
+	m.checkedOperations = append(m.checkedOperations, name)
+	return m.finishedOperations[name], nil
+}
+
+func (m *fakeTagsManager) WarmCache(_ context.Context, parent string, include func(key string) bool) (int, error) {
+	m.warmedParents = append(m.warmedParents, parent)
+	for _, key := range m.keys[parent] {
+		if include(key.ShortName) {
+			m.warmedKeys = append(m.warmedKeys, key.ShortName)
+		}
+	}
+	return len(m.warmedKeys), nil
+}
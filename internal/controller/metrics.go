@@ -0,0 +1,33 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+var (
+	tagBindingDriftTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "tagging_operator_tag_binding_drift_total",
+		Help: "Number of differences detected between the expected tag bindings and the bindings in GCP.",
+	}, []string{"kind", "type"})
+)
+
+func init() {
+	metrics.Registry.MustRegister(tagBindingDriftTotal)
+}
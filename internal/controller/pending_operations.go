@@ -0,0 +1,87 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	taggingv1alpha1 "github.com/deliveryhero/gcp-config-connector-tagging-operator/api/v1alpha1"
+	"github.com/deliveryhero/gcp-config-connector-tagging-operator/internal/gcp"
+	"github.com/deliveryhero/gcp-config-connector-tagging-operator/internal/policy"
+)
+
+// pendingOperationPollInterval is how often resources waiting for the creation of tag keys or values are requeued.
+const pendingOperationPollInterval = 5 * time.Second
+
+// runningOperations polls the operations recorded in the TagAssignment of the resource by previous reconciliations,
+// returning those which are still running. Finished operations are dropped, their tags are looked up again.
+func (r *TaggableResourceReconciler[T, P, PT]) runningOperations(ctx context.Context, resource PT) ([]taggingv1alpha1.PendingOperation, error) {
+	log := log.FromContext(ctx)
+
+	var assignment taggingv1alpha1.TagAssignment
+	key := types.NamespacedName{Namespace: resource.GetNamespace(), Name: tagAssignmentName(resource)}
+	if err := r.Get(ctx, key, &assignment); err != nil {
+		return nil, client.IgnoreNotFound(err)
+	}
+
+	var running []taggingv1alpha1.PendingOperation
+	for _, op := range assignment.Status.PendingOperations {
+		done, err := r.TagsManager.CheckOperation(ctx, op.Kind, op.Operation)
+		if err != nil {
+			if !done {
+				// polling failed, try again with the next reconciliation
+				log.Error(err, "unable to poll operation", "operation", op.Operation)
+				running = append(running, op)
+				continue
+			}
+			// the creation is retried by looking up the tag again
+			r.recordGCPError(resource, err)
+			continue
+		}
+		if !done {
+			running = append(running, op)
+		}
+	}
+	return running, nil
+}
+
+// pendingOperationFor returns the running operation creating the tag key or value of tag, if any.
+func pendingOperationFor(operations []taggingv1alpha1.PendingOperation, tag policy.Tag) *taggingv1alpha1.PendingOperation {
+	for i, op := range operations {
+		if op.TagKey == tag.Key && (op.Kind == gcp.KindTagKey || op.TagValue == tag.Value) {
+			return &operations[i]
+		}
+	}
+	return nil
+}
+
+func newPendingOperation(pending *gcp.OperationPendingError, tag policy.Tag) taggingv1alpha1.PendingOperation {
+	op := taggingv1alpha1.PendingOperation{
+		Operation: pending.Operation,
+		Kind:      pending.Kind,
+		TagKey:    tag.Key,
+	}
+	if pending.Kind == gcp.KindTagValue {
+		op.TagValue = tag.Value
+	}
+	return op
+}
@@ -0,0 +1,79 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	"cloud.google.com/go/resourcemanager/apiv3/resourcemanagerpb"
+	ccv1alpha1 "github.com/GoogleCloudPlatform/k8s-config-connector/pkg/clients/generated/apis/k8s/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ResourceReferenceProvider is implemented by metadata providers of resources whose ID contains the ID of another
+// Config Connector resource they reference by name, like the dataset of a table. The reconciler uses ResolveResourceID
+// instead of GetResourceID and does not bind tags before the referenced resource exists.
+type ResourceReferenceProvider[R any] interface {
+	// ResolveResourceID returns the full resource name of r like GetResourceID, or false if a referenced resource
+	// does not exist yet.
+	ResolveResourceID(ctx context.Context, resolver *ReferenceResolver, projectInfo *resourcemanagerpb.Project, r *R) (string, bool, error)
+}
+
+// ReferenceResolver reads Config Connector resources referenced by name.
+type ReferenceResolver struct {
+	reader client.Reader
+}
+
+// NewReferenceResolver creates a ReferenceResolver reading referenced resources with reader.
+func NewReferenceResolver(reader client.Reader) *ReferenceResolver {
+	return &ReferenceResolver{reader: reader}
+}
+
+// Resolve reads the resource referenced by ref by name into obj and returns its project and ID, which is its
+// spec.resourceID or else its name. The resource is looked up in namespace, unless ref names another one.
+// It returns false if the resource does not exist.
+func (rr *ReferenceResolver) Resolve(ctx context.Context, namespace string, ref ccv1alpha1.ResourceRef, obj client.Object) (string, string, bool, error) {
+	key := projectRefObjectKey(namespace, &ref)
+	if err := rr.reader.Get(ctx, key, obj); err != nil {
+		if errors.IsNotFound(err) {
+			return "", "", false, nil
+		}
+		return "", "", false, fmt.Errorf("failed to fetch referenced resource %s: %w", key, err)
+	}
+
+	u, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return "", "", false, err
+	}
+	resourceID, _, err := unstructured.NestedString(u, "spec", "resourceID")
+	if err != nil {
+		return "", "", false, err
+	}
+	if resourceID == "" {
+		resourceID = obj.GetName()
+	}
+
+	projectID, _, err := resolveProjectID(ctx, rr.reader, obj)
+	if err != nil {
+		return "", "", false, err
+	}
+	return projectID, resourceID, true, nil
+}
@@ -0,0 +1,45 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"fmt"
+
+	"cloud.google.com/go/resourcemanager/apiv3/resourcemanagerpb"
+	artifactregistryv1beta1 "github.com/GoogleCloudPlatform/k8s-config-connector/pkg/clients/generated/apis/artifactregistry/v1beta1"
+
+	"github.com/deliveryhero/gcp-config-connector-tagging-operator/internal/controller"
+)
+
+// +kubebuilder:rbac:groups=artifactregistry.cnrm.cloud.google.com,resources=artifactregistryrepositories,verbs=get;list;watch;update
+
+var _ controller.ResourceMetadataProvider[artifactregistryv1beta1.ArtifactRegistryRepository] = &ArtifactRegistryRepositoryMetadataProvider{}
+
+type ArtifactRegistryRepositoryMetadataProvider struct{}
+
+func (in *ArtifactRegistryRepositoryMetadataProvider) GetResourceLocation(r *artifactregistryv1beta1.ArtifactRegistryRepository) string {
+	return r.Spec.Location
+}
+
+func (in *ArtifactRegistryRepositoryMetadataProvider) GetResourceID(projectInfo *resourcemanagerpb.Project, r *artifactregistryv1beta1.ArtifactRegistryRepository) string {
+	name := r.Name
+	if r.Spec.ResourceID != nil {
+		name = *r.Spec.ResourceID
+	}
+
+	return fmt.Sprintf("//artifactregistry.googleapis.com/projects/%s/locations/%s/repositories/%s", projectInfo.ProjectId, r.Spec.Location, name)
+}
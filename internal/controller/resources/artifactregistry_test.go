@@ -0,0 +1,80 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"testing"
+
+	"cloud.google.com/go/resourcemanager/apiv3/resourcemanagerpb"
+	artifactregistryv1beta1 "github.com/GoogleCloudPlatform/k8s-config-connector/pkg/clients/generated/apis/artifactregistry/v1beta1"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+)
+
+func TestArtifactRegistryRepositoryMetadataProvider_GetResourceID(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name        string
+		r           *artifactregistryv1beta1.ArtifactRegistryRepository
+		projectInfo *resourcemanagerpb.Project
+		want        string
+	}{
+		{
+			name: "with generated name",
+			r: &artifactregistryv1beta1.ArtifactRegistryRepository{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "test-repository",
+				},
+				Spec: artifactregistryv1beta1.ArtifactRegistryRepositorySpec{
+					Location: "europe-west1",
+				},
+			},
+			projectInfo: &resourcemanagerpb.Project{
+				ProjectId: "test-project",
+			},
+			want: "//artifactregistry.googleapis.com/projects/test-project/locations/europe-west1/repositories/test-repository",
+		},
+		{
+			name: "with overridden resource id",
+			r: &artifactregistryv1beta1.ArtifactRegistryRepository{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "test-repository",
+				},
+				Spec: artifactregistryv1beta1.ArtifactRegistryRepositorySpec{
+					Location:   "europe-west1",
+					ResourceID: ptr.To("overridden-repository-id"),
+				},
+			},
+			projectInfo: &resourcemanagerpb.Project{
+				ProjectId: "test-project",
+			},
+			want: "//artifactregistry.googleapis.com/projects/test-project/locations/europe-west1/repositories/overridden-repository-id",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			p := &ArtifactRegistryRepositoryMetadataProvider{}
+
+			got := p.GetResourceID(tc.projectInfo, tc.r)
+
+			require.Equal(t, tc.want, got)
+		})
+	}
+}
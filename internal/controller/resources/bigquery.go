@@ -0,0 +1,91 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"cloud.google.com/go/resourcemanager/apiv3/resourcemanagerpb"
+	bigqueryv1beta1 "github.com/GoogleCloudPlatform/k8s-config-connector/pkg/clients/generated/apis/bigquery/v1beta1"
+	"k8s.io/utils/ptr"
+
+	"github.com/deliveryhero/gcp-config-connector-tagging-operator/internal/controller"
+)
+
+// +kubebuilder:rbac:groups=bigquery.cnrm.cloud.google.com,resources=bigquerydatasets;bigquerytables,verbs=get;list;watch;update
+
+var _ controller.ResourceMetadataProvider[bigqueryv1beta1.BigQueryDataset] = &BigQueryDatasetMetadataProvider{}
+var _ controller.ResourceMetadataProvider[bigqueryv1beta1.BigQueryTable] = &BigQueryTableMetadataProvider{}
+var _ controller.ResourceStatusProvider[bigqueryv1beta1.BigQueryTable] = &BigQueryTableMetadataProvider{}
+var _ controller.ResourceReferenceProvider[bigqueryv1beta1.BigQueryTable] = &BigQueryTableMetadataProvider{}
+
+// defaultBigQueryLocation is the location of datasets created without one.
+const defaultBigQueryLocation = "US"
+
+type BigQueryDatasetMetadataProvider struct{}
+
+// GetResourceLocation returns the location of the dataset in lower case, as tag bindings expect multi-regions like "eu".
+func (in *BigQueryDatasetMetadataProvider) GetResourceLocation(r *bigqueryv1beta1.BigQueryDataset) string {
+	return strings.ToLower(ptr.Deref(r.Spec.Location, defaultBigQueryLocation))
+}
+
+func (in *BigQueryDatasetMetadataProvider) GetResourceID(projectInfo *resourcemanagerpb.Project, r *bigqueryv1beta1.BigQueryDataset) string {
+	name := r.Name
+	if r.Spec.ResourceID != nil {
+		name = *r.Spec.ResourceID
+	}
+
+	return fmt.Sprintf("//bigquery.googleapis.com/projects/%s/datasets/%s", projectInfo.ProjectId, name)
+}
+
+type BigQueryTableMetadataProvider struct{}
+
+// GetResourceLocation returns the location of the table in lower case. Tables inherit the location of their dataset,
+// which Config Connector reports in the status of the table.
+func (in *BigQueryTableMetadataProvider) GetResourceLocation(r *bigqueryv1beta1.BigQueryTable) string {
+	return strings.ToLower(ptr.Deref(r.Status.Location, ""))
+}
+
+func (in *BigQueryTableMetadataProvider) HasResourceStatus(r *bigqueryv1beta1.BigQueryTable) bool {
+	return ptr.Deref(r.Status.Location, "") != ""
+}
+
+func (in *BigQueryTableMetadataProvider) GetResourceID(projectInfo *resourcemanagerpb.Project, r *bigqueryv1beta1.BigQueryTable) string {
+	projectID, dataset := resourceRefID(projectInfo.ProjectId, r.Spec.DatasetRef)
+	return bigQueryTableID(projectID, dataset, r)
+}
+
+// ResolveResourceID reads datasets referenced by name, as their ID is only their name if they have no resourceID,
+// and they may belong to another project than the table.
+func (in *BigQueryTableMetadataProvider) ResolveResourceID(ctx context.Context, resolver *controller.ReferenceResolver, projectInfo *resourcemanagerpb.Project, r *bigqueryv1beta1.BigQueryTable) (string, bool, error) {
+	projectID, dataset, ok, err := resolveResourceRef(ctx, resolver, r.Namespace, projectInfo.ProjectId, r.Spec.DatasetRef, &bigqueryv1beta1.BigQueryDataset{})
+	if err != nil || !ok {
+		return "", false, err
+	}
+	return bigQueryTableID(projectID, dataset, r), true, nil
+}
+
+func bigQueryTableID(projectID, dataset string, r *bigqueryv1beta1.BigQueryTable) string {
+	name := r.Name
+	if r.Spec.ResourceID != nil {
+		name = *r.Spec.ResourceID
+	}
+
+	return fmt.Sprintf("//bigquery.googleapis.com/projects/%s/datasets/%s/tables/%s", projectID, dataset, name)
+}
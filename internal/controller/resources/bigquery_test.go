@@ -0,0 +1,241 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"context"
+	"testing"
+
+	"cloud.google.com/go/resourcemanager/apiv3/resourcemanagerpb"
+	bigqueryv1beta1 "github.com/GoogleCloudPlatform/k8s-config-connector/pkg/clients/generated/apis/bigquery/v1beta1"
+	ccv1alpha1 "github.com/GoogleCloudPlatform/k8s-config-connector/pkg/clients/generated/apis/k8s/v1alpha1"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+)
+
+func TestBigQueryDatasetMetadataProvider_GetResourceID(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name        string
+		r           *bigqueryv1beta1.BigQueryDataset
+		projectInfo *resourcemanagerpb.Project
+		want        string
+	}{
+		{
+			name: "with generated name",
+			r: &bigqueryv1beta1.BigQueryDataset{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "test_dataset",
+				},
+			},
+			projectInfo: &resourcemanagerpb.Project{
+				ProjectId: "test-project",
+			},
+			want: "//bigquery.googleapis.com/projects/test-project/datasets/test_dataset",
+		},
+		{
+			name: "with overridden resource id",
+			r: &bigqueryv1beta1.BigQueryDataset{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "test-dataset",
+				},
+				Spec: bigqueryv1beta1.BigQueryDatasetSpec{
+					ResourceID: ptr.To("overridden_dataset_id"),
+				},
+			},
+			projectInfo: &resourcemanagerpb.Project{
+				ProjectId: "test-project",
+			},
+			want: "//bigquery.googleapis.com/projects/test-project/datasets/overridden_dataset_id",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			p := &BigQueryDatasetMetadataProvider{}
+
+			got := p.GetResourceID(tc.projectInfo, tc.r)
+
+			require.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestBigQueryDatasetMetadataProvider_GetResourceLocation(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name     string
+		location *string
+		want     string
+	}{
+		{name: "multi-region", location: ptr.To("EU"), want: "eu"},
+		{name: "region", location: ptr.To("europe-west1"), want: "europe-west1"},
+		{name: "default", want: "us"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			p := &BigQueryDatasetMetadataProvider{}
+
+			got := p.GetResourceLocation(&bigqueryv1beta1.BigQueryDataset{
+				Spec: bigqueryv1beta1.BigQueryDatasetSpec{Location: tc.location},
+			})
+
+			require.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestBigQueryTableMetadataProvider_GetResourceID(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name        string
+		r           *bigqueryv1beta1.BigQueryTable
+		projectInfo *resourcemanagerpb.Project
+		want        string
+	}{
+		{
+			name: "with dataset name",
+			r: &bigqueryv1beta1.BigQueryTable{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "test_table",
+				},
+				Spec: bigqueryv1beta1.BigQueryTableSpec{
+					DatasetRef: ccv1alpha1.ResourceRef{Name: "test-dataset"},
+				},
+			},
+			projectInfo: &resourcemanagerpb.Project{
+				ProjectId: "test-project",
+			},
+			want: "//bigquery.googleapis.com/projects/test-project/datasets/test-dataset/tables/test_table",
+		},
+		{
+			name: "with overridden resource id and external dataset id",
+			r: &bigqueryv1beta1.BigQueryTable{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "test-table",
+				},
+				Spec: bigqueryv1beta1.BigQueryTableSpec{
+					DatasetRef: ccv1alpha1.ResourceRef{External: "external_dataset"},
+					ResourceID: ptr.To("overridden_table_id"),
+				},
+			},
+			projectInfo: &resourcemanagerpb.Project{
+				ProjectId: "test-project",
+			},
+			want: "//bigquery.googleapis.com/projects/test-project/datasets/external_dataset/tables/overridden_table_id",
+		},
+		{
+			name: "with external dataset in another project",
+			r: &bigqueryv1beta1.BigQueryTable{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "test_table",
+				},
+				Spec: bigqueryv1beta1.BigQueryTableSpec{
+					DatasetRef: ccv1alpha1.ResourceRef{External: "projects/other-project/datasets/external_dataset"},
+				},
+			},
+			projectInfo: &resourcemanagerpb.Project{
+				ProjectId: "test-project",
+			},
+			want: "//bigquery.googleapis.com/projects/other-project/datasets/external_dataset/tables/test_table",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			p := &BigQueryTableMetadataProvider{}
+
+			got := p.GetResourceID(tc.projectInfo, tc.r)
+
+			require.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestBigQueryTableMetadataProvider_ResolveResourceID(t *testing.T) {
+	t.Parallel()
+
+	dataset := &bigqueryv1beta1.BigQueryDataset{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "test-dataset",
+			Namespace:   "default",
+			Annotations: map[string]string{"cnrm.cloud.google.com/project-id": "dataset-project"},
+		},
+		Spec: bigqueryv1beta1.BigQueryDatasetSpec{
+			ResourceID: ptr.To("test_dataset"),
+		},
+	}
+	resolver := newTestReferenceResolver(t, dataset)
+	projectInfo := &resourcemanagerpb.Project{ProjectId: "test-project"}
+
+	testCases := []struct {
+		name         string
+		datasetRef   ccv1alpha1.ResourceRef
+		want         string
+		wantResolved bool
+	}{
+		{
+			name:         "dataset name",
+			datasetRef:   ccv1alpha1.ResourceRef{Name: "test-dataset"},
+			want:         "//bigquery.googleapis.com/projects/dataset-project/datasets/test_dataset/tables/test_table",
+			wantResolved: true,
+		},
+		{
+			name:       "missing dataset",
+			datasetRef: ccv1alpha1.ResourceRef{Name: "missing-dataset"},
+		},
+		{
+			name:         "external dataset",
+			datasetRef:   ccv1alpha1.ResourceRef{External: "external_dataset"},
+			want:         "//bigquery.googleapis.com/projects/test-project/datasets/external_dataset/tables/test_table",
+			wantResolved: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			p := &BigQueryTableMetadataProvider{}
+
+			got, resolved, err := p.ResolveResourceID(context.Background(), resolver, projectInfo, &bigqueryv1beta1.BigQueryTable{
+				ObjectMeta: metav1.ObjectMeta{Name: "test_table", Namespace: "default"},
+				Spec:       bigqueryv1beta1.BigQueryTableSpec{DatasetRef: tc.datasetRef},
+			})
+
+			require.NoError(t, err)
+			require.Equal(t, tc.wantResolved, resolved)
+			require.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestBigQueryTableMetadataProvider_GetResourceLocation(t *testing.T) {
+	t.Parallel()
+
+	p := &BigQueryTableMetadataProvider{}
+
+	table := &bigqueryv1beta1.BigQueryTable{}
+	require.False(t, p.HasResourceStatus(table))
+
+	table.Status.Location = ptr.To("EU")
+	require.True(t, p.HasResourceStatus(table))
+	require.Equal(t, "eu", p.GetResourceLocation(table))
+}
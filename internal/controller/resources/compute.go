@@ -0,0 +1,107 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"fmt"
+	"strings"
+
+	"cloud.google.com/go/resourcemanager/apiv3/resourcemanagerpb"
+	computev1beta1 "github.com/GoogleCloudPlatform/k8s-config-connector/pkg/clients/generated/apis/compute/v1beta1"
+	"k8s.io/utils/ptr"
+
+	"github.com/deliveryhero/gcp-config-connector-tagging-operator/internal/controller"
+)
+
+// +kubebuilder:rbac:groups=compute.cnrm.cloud.google.com,resources=computeinstances;computedisks,verbs=get;list;watch;update
+
+var _ controller.ResourceMetadataProvider[computev1beta1.ComputeInstance] = &ComputeInstanceMetadataProvider{}
+var _ controller.ResourceStatusProvider[computev1beta1.ComputeInstance] = &ComputeInstanceMetadataProvider{}
+var _ controller.FirewallTagsProvider = &ComputeInstanceMetadataProvider{}
+var _ controller.ResourceMetadataProvider[computev1beta1.ComputeDisk] = &ComputeDiskMetadataProvider{}
+var _ controller.ResourceStatusProvider[computev1beta1.ComputeDisk] = &ComputeDiskMetadataProvider{}
+
+type ComputeInstanceMetadataProvider struct{}
+
+// GetResourceLocation returns the zone of the instance, which Config Connector defaults if it is not set in the spec.
+func (in *ComputeInstanceMetadataProvider) GetResourceLocation(r *computev1beta1.ComputeInstance) string {
+	if r.Spec.Zone != nil {
+		return *r.Spec.Zone
+	}
+	return selfLinkSegment(ptr.Deref(r.Status.SelfLink, ""), "zones")
+}
+
+// HasResourceStatus reports whether Config Connector created the instance, tags are bound to its numeric ID.
+func (in *ComputeInstanceMetadataProvider) HasResourceStatus(r *computev1beta1.ComputeInstance) bool {
+	return ptr.Deref(r.Status.InstanceId, "") != "" && in.GetResourceLocation(r) != ""
+}
+
+// SupportsFirewallTags returns true, secure tags are bound to VM instances.
+func (in *ComputeInstanceMetadataProvider) SupportsFirewallTags() bool {
+	return true
+}
+
+func (in *ComputeInstanceMetadataProvider) GetResourceID(projectInfo *resourcemanagerpb.Project, r *computev1beta1.ComputeInstance) string {
+	projectNumber := strings.TrimPrefix(projectInfo.Name, "projects/")
+
+	return fmt.Sprintf("//compute.googleapis.com/projects/%s/zones/%s/instances/%s",
+		projectNumber, in.GetResourceLocation(r), ptr.Deref(r.Status.InstanceId, ""))
+}
+
+type ComputeDiskMetadataProvider struct{}
+
+// GetResourceLocation returns the zone of zonal disks and the region of regional disks.
+func (in *ComputeDiskMetadataProvider) GetResourceLocation(r *computev1beta1.ComputeDisk) string {
+	return r.Spec.Location
+}
+
+// HasResourceStatus reports whether Config Connector created the disk.
+func (in *ComputeDiskMetadataProvider) HasResourceStatus(r *computev1beta1.ComputeDisk) bool {
+	return ptr.Deref(r.Status.SelfLink, "") != ""
+}
+
+// GetResourceID identifies the disk by name, Config Connector does not report the numeric ID of disks.
+func (in *ComputeDiskMetadataProvider) GetResourceID(projectInfo *resourcemanagerpb.Project, r *computev1beta1.ComputeDisk) string {
+	name := r.Name
+	if r.Spec.ResourceID != nil {
+		name = *r.Spec.ResourceID
+	}
+
+	projectNumber := strings.TrimPrefix(projectInfo.Name, "projects/")
+	scope := "regions"
+	if isZone(r.Spec.Location) {
+		scope = "zones"
+	}
+
+	return fmt.Sprintf("//compute.googleapis.com/projects/%s/%s/%s/disks/%s", projectNumber, scope, r.Spec.Location, name)
+}
+
+// isZone reports whether location is a zone like "europe-west1-b" rather than a region like "europe-west1".
+func isZone(location string) bool {
+	return strings.Count(location, "-") == 2
+}
+
+// selfLinkSegment returns the path segment following collection in a self link, e.g. the zone of an instance.
+func selfLinkSegment(selfLink string, collection string) string {
+	parts := strings.Split(selfLink, "/")
+	for i := 0; i < len(parts)-1; i++ {
+		if parts[i] == collection {
+			return parts[i+1]
+		}
+	}
+	return ""
+}
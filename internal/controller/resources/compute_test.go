@@ -0,0 +1,172 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"testing"
+
+	"cloud.google.com/go/resourcemanager/apiv3/resourcemanagerpb"
+	computev1beta1 "github.com/GoogleCloudPlatform/k8s-config-connector/pkg/clients/generated/apis/compute/v1beta1"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+)
+
+func TestComputeInstanceMetadataProvider_GetResourceID(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name         string
+		r            *computev1beta1.ComputeInstance
+		projectInfo  *resourcemanagerpb.Project
+		want         string
+		wantLocation string
+	}{
+		{
+			name: "with zone in spec",
+			r: &computev1beta1.ComputeInstance{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "test-instance",
+				},
+				Spec: computev1beta1.ComputeInstanceSpec{
+					Zone: ptr.To("europe-west1-b"),
+				},
+				Status: computev1beta1.ComputeInstanceStatus{
+					InstanceId: ptr.To("1234567890"),
+				},
+			},
+			projectInfo: &resourcemanagerpb.Project{
+				Name:      "projects/123456",
+				ProjectId: "test-project",
+			},
+			want:         "//compute.googleapis.com/projects/123456/zones/europe-west1-b/instances/1234567890",
+			wantLocation: "europe-west1-b",
+		},
+		{
+			name: "with zone from self link",
+			r: &computev1beta1.ComputeInstance{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "test-instance",
+				},
+				Status: computev1beta1.ComputeInstanceStatus{
+					InstanceId: ptr.To("1234567890"),
+					SelfLink:   ptr.To("https://www.googleapis.com/compute/v1/projects/test-project/zones/europe-west4-a/instances/test-instance"),
+				},
+			},
+			projectInfo: &resourcemanagerpb.Project{
+				Name:      "projects/123456",
+				ProjectId: "test-project",
+			},
+			want:         "//compute.googleapis.com/projects/123456/zones/europe-west4-a/instances/1234567890",
+			wantLocation: "europe-west4-a",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			p := &ComputeInstanceMetadataProvider{}
+
+			require.True(t, p.HasResourceStatus(tc.r))
+			require.Equal(t, tc.wantLocation, p.GetResourceLocation(tc.r))
+			require.Equal(t, tc.want, p.GetResourceID(tc.projectInfo, tc.r))
+		})
+	}
+}
+
+func TestComputeInstanceMetadataProvider_HasResourceStatus(t *testing.T) {
+	t.Parallel()
+
+	p := &ComputeInstanceMetadataProvider{}
+
+	instance := &computev1beta1.ComputeInstance{}
+	require.False(t, p.HasResourceStatus(instance))
+
+	instance.Status.InstanceId = ptr.To("1234567890")
+	require.False(t, p.HasResourceStatus(instance), "the zone is unknown")
+
+	instance.Spec.Zone = ptr.To("europe-west1-b")
+	require.True(t, p.HasResourceStatus(instance))
+	require.True(t, p.SupportsFirewallTags())
+}
+
+func TestComputeDiskMetadataProvider_GetResourceID(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name        string
+		r           *computev1beta1.ComputeDisk
+		projectInfo *resourcemanagerpb.Project
+		want        string
+	}{
+		{
+			name: "zonal disk",
+			r: &computev1beta1.ComputeDisk{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "test-disk",
+				},
+				Spec: computev1beta1.ComputeDiskSpec{
+					Location: "europe-west1-b",
+				},
+			},
+			projectInfo: &resourcemanagerpb.Project{
+				Name:      "projects/123456",
+				ProjectId: "test-project",
+			},
+			want: "//compute.googleapis.com/projects/123456/zones/europe-west1-b/disks/test-disk",
+		},
+		{
+			name: "regional disk with overridden resource id",
+			r: &computev1beta1.ComputeDisk{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "test-disk",
+				},
+				Spec: computev1beta1.ComputeDiskSpec{
+					Location:   "europe-west1",
+					ResourceID: ptr.To("overridden-disk-id"),
+				},
+			},
+			projectInfo: &resourcemanagerpb.Project{
+				Name:      "projects/123456",
+				ProjectId: "test-project",
+			},
+			want: "//compute.googleapis.com/projects/123456/regions/europe-west1/disks/overridden-disk-id",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			p := &ComputeDiskMetadataProvider{}
+
+			got := p.GetResourceID(tc.projectInfo, tc.r)
+
+			require.Equal(t, tc.want, got)
+			require.Equal(t, tc.r.Spec.Location, p.GetResourceLocation(tc.r))
+		})
+	}
+}
+
+func TestComputeDiskMetadataProvider_HasResourceStatus(t *testing.T) {
+	t.Parallel()
+
+	p := &ComputeDiskMetadataProvider{}
+
+	disk := &computev1beta1.ComputeDisk{}
+	require.False(t, p.HasResourceStatus(disk))
+
+	disk.Status.SelfLink = ptr.To("https://www.googleapis.com/compute/v1/projects/test-project/zones/europe-west1-b/disks/test-disk")
+	require.True(t, p.HasResourceStatus(disk))
+}
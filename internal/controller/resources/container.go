@@ -0,0 +1,90 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"context"
+	"fmt"
+
+	"cloud.google.com/go/resourcemanager/apiv3/resourcemanagerpb"
+	containerv1beta1 "github.com/GoogleCloudPlatform/k8s-config-connector/pkg/clients/generated/apis/container/v1beta1"
+
+	"github.com/deliveryhero/gcp-config-connector-tagging-operator/internal/controller"
+)
+
+// +kubebuilder:rbac:groups=container.cnrm.cloud.google.com,resources=containerclusters;containernodepools,verbs=get;list;watch;update
+
+var _ controller.ResourceMetadataProvider[containerv1beta1.ContainerCluster] = &ContainerClusterMetadataProvider{}
+var _ controller.ResourceMetadataProvider[containerv1beta1.ContainerNodePool] = &ContainerNodePoolMetadataProvider{}
+var _ controller.ResourceReferenceProvider[containerv1beta1.ContainerNodePool] = &ContainerNodePoolMetadataProvider{}
+
+type ContainerClusterMetadataProvider struct{}
+
+// GetResourceLocation returns the region of regional clusters and the zone of zonal clusters.
+func (in *ContainerClusterMetadataProvider) GetResourceLocation(r *containerv1beta1.ContainerCluster) string {
+	return r.Spec.Location
+}
+
+func (in *ContainerClusterMetadataProvider) GetResourceID(projectInfo *resourcemanagerpb.Project, r *containerv1beta1.ContainerCluster) string {
+	name := r.Name
+	if r.Spec.ResourceID != nil {
+		name = *r.Spec.ResourceID
+	}
+
+	return fmt.Sprintf("//container.googleapis.com/projects/%s/%s/%s/clusters/%s",
+		projectInfo.ProjectId, containerLocationScope(r.Spec.Location), r.Spec.Location, name)
+}
+
+type ContainerNodePoolMetadataProvider struct{}
+
+// GetResourceLocation returns the location of the cluster of the node pool.
+func (in *ContainerNodePoolMetadataProvider) GetResourceLocation(r *containerv1beta1.ContainerNodePool) string {
+	return r.Spec.Location
+}
+
+func (in *ContainerNodePoolMetadataProvider) GetResourceID(projectInfo *resourcemanagerpb.Project, r *containerv1beta1.ContainerNodePool) string {
+	projectID, cluster := resourceRefID(projectInfo.ProjectId, r.Spec.ClusterRef)
+	return containerNodePoolID(projectID, cluster, r)
+}
+
+// ResolveResourceID reads clusters referenced by name, which may have a resourceID or belong to another project.
+func (in *ContainerNodePoolMetadataProvider) ResolveResourceID(ctx context.Context, resolver *controller.ReferenceResolver, projectInfo *resourcemanagerpb.Project, r *containerv1beta1.ContainerNodePool) (string, bool, error) {
+	projectID, cluster, ok, err := resolveResourceRef(ctx, resolver, r.Namespace, projectInfo.ProjectId, r.Spec.ClusterRef, &containerv1beta1.ContainerCluster{})
+	if err != nil || !ok {
+		return "", false, err
+	}
+	return containerNodePoolID(projectID, cluster, r), true, nil
+}
+
+func containerNodePoolID(projectID, cluster string, r *containerv1beta1.ContainerNodePool) string {
+	name := r.Name
+	if r.Spec.ResourceID != nil {
+		name = *r.Spec.ResourceID
+	}
+
+	return fmt.Sprintf("//container.googleapis.com/projects/%s/%s/%s/clusters/%s/nodePools/%s",
+		projectID, containerLocationScope(r.Spec.Location), r.Spec.Location, cluster, name)
+}
+
+// containerLocationScope returns the collection of a GKE location in full resource names, which name zonal clusters
+// in "zones" and regional clusters in "locations".
+func containerLocationScope(location string) string {
+	if isZone(location) {
+		return "zones"
+	}
+	return "locations"
+}
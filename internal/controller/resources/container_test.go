@@ -0,0 +1,173 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"context"
+	"testing"
+
+	"cloud.google.com/go/resourcemanager/apiv3/resourcemanagerpb"
+	containerv1beta1 "github.com/GoogleCloudPlatform/k8s-config-connector/pkg/clients/generated/apis/container/v1beta1"
+	ccv1alpha1 "github.com/GoogleCloudPlatform/k8s-config-connector/pkg/clients/generated/apis/k8s/v1alpha1"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+)
+
+func TestContainerClusterMetadataProvider_GetResourceID(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name        string
+		r           *containerv1beta1.ContainerCluster
+		projectInfo *resourcemanagerpb.Project
+		want        string
+	}{
+		{
+			name: "regional cluster",
+			r: &containerv1beta1.ContainerCluster{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "test-cluster",
+				},
+				Spec: containerv1beta1.ContainerClusterSpec{
+					Location: "europe-west1",
+				},
+			},
+			projectInfo: &resourcemanagerpb.Project{
+				ProjectId: "test-project",
+			},
+			want: "//container.googleapis.com/projects/test-project/locations/europe-west1/clusters/test-cluster",
+		},
+		{
+			name: "zonal cluster with overridden resource id",
+			r: &containerv1beta1.ContainerCluster{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "test-cluster",
+				},
+				Spec: containerv1beta1.ContainerClusterSpec{
+					Location:   "europe-west1-b",
+					ResourceID: ptr.To("overridden-cluster-id"),
+				},
+			},
+			projectInfo: &resourcemanagerpb.Project{
+				ProjectId: "test-project",
+			},
+			want: "//container.googleapis.com/projects/test-project/zones/europe-west1-b/clusters/overridden-cluster-id",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			p := &ContainerClusterMetadataProvider{}
+
+			got := p.GetResourceID(tc.projectInfo, tc.r)
+
+			require.Equal(t, tc.want, got)
+			require.Equal(t, tc.r.Spec.Location, p.GetResourceLocation(tc.r))
+		})
+	}
+}
+
+func TestContainerNodePoolMetadataProvider_GetResourceID(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name        string
+		r           *containerv1beta1.ContainerNodePool
+		projectInfo *resourcemanagerpb.Project
+		want        string
+	}{
+		{
+			name: "regional node pool",
+			r: &containerv1beta1.ContainerNodePool{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "test-pool",
+				},
+				Spec: containerv1beta1.ContainerNodePoolSpec{
+					ClusterRef: ccv1alpha1.ResourceRef{Name: "test-cluster"},
+					Location:   "europe-west1",
+				},
+			},
+			projectInfo: &resourcemanagerpb.Project{
+				ProjectId: "test-project",
+			},
+			want: "//container.googleapis.com/projects/test-project/locations/europe-west1/clusters/test-cluster/nodePools/test-pool",
+		},
+		{
+			name: "zonal node pool of external cluster",
+			r: &containerv1beta1.ContainerNodePool{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "test-pool",
+				},
+				Spec: containerv1beta1.ContainerNodePoolSpec{
+					ClusterRef: ccv1alpha1.ResourceRef{External: "projects/other-project/locations/europe-west1-b/clusters/external-cluster"},
+					Location:   "europe-west1-b",
+					ResourceID: ptr.To("overridden-pool-id"),
+				},
+			},
+			projectInfo: &resourcemanagerpb.Project{
+				ProjectId: "test-project",
+			},
+			want: "//container.googleapis.com/projects/other-project/zones/europe-west1-b/clusters/external-cluster/nodePools/overridden-pool-id",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			p := &ContainerNodePoolMetadataProvider{}
+
+			got := p.GetResourceID(tc.projectInfo, tc.r)
+
+			require.Equal(t, tc.want, got)
+			require.Equal(t, tc.r.Spec.Location, p.GetResourceLocation(tc.r))
+		})
+	}
+}
+
+func TestContainerNodePoolMetadataProvider_ResolveResourceID(t *testing.T) {
+	t.Parallel()
+
+	cluster := &containerv1beta1.ContainerCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-cluster",
+			Namespace: "clusters",
+		},
+		Spec: containerv1beta1.ContainerClusterSpec{
+			Location:   "europe-west1",
+			ResourceID: ptr.To("overridden-cluster-id"),
+		},
+	}
+	p := &ContainerNodePoolMetadataProvider{}
+	pool := &containerv1beta1.ContainerNodePool{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-pool", Namespace: "default"},
+		Spec: containerv1beta1.ContainerNodePoolSpec{
+			ClusterRef: ccv1alpha1.ResourceRef{Name: "test-cluster", Namespace: "clusters"},
+			Location:   "europe-west1",
+		},
+	}
+
+	got, resolved, err := p.ResolveResourceID(context.Background(), newTestReferenceResolver(t), &resourcemanagerpb.Project{ProjectId: "test-project"}, pool)
+	require.NoError(t, err)
+	require.False(t, resolved)
+	require.Empty(t, got)
+
+	got, resolved, err = p.ResolveResourceID(context.Background(), newTestReferenceResolver(t, cluster), &resourcemanagerpb.Project{ProjectId: "test-project"}, pool)
+	require.NoError(t, err)
+	require.True(t, resolved)
+	// the cluster has no project annotation, so Config Connector uses the name of its namespace as project
+	require.Equal(t, "//container.googleapis.com/projects/clusters/locations/europe-west1/clusters/overridden-cluster-id/nodePools/test-pool", got)
+}
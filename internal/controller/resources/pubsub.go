@@ -0,0 +1,63 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"fmt"
+
+	"cloud.google.com/go/resourcemanager/apiv3/resourcemanagerpb"
+	pubsubv1beta1 "github.com/GoogleCloudPlatform/k8s-config-connector/pkg/clients/generated/apis/pubsub/v1beta1"
+
+	"github.com/deliveryhero/gcp-config-connector-tagging-operator/internal/controller"
+)
+
+// +kubebuilder:rbac:groups=pubsub.cnrm.cloud.google.com,resources=pubsubtopics;pubsubsubscriptions,verbs=get;list;watch;update
+
+var _ controller.ResourceMetadataProvider[pubsubv1beta1.PubSubTopic] = &PubSubTopicMetadataProvider{}
+var _ controller.ResourceMetadataProvider[pubsubv1beta1.PubSubSubscription] = &PubSubSubscriptionMetadataProvider{}
+
+type PubSubTopicMetadataProvider struct{}
+
+// GetResourceLocation returns the global location, topics are global resources.
+func (in *PubSubTopicMetadataProvider) GetResourceLocation(_ *pubsubv1beta1.PubSubTopic) string {
+	return controller.GlobalLocation
+}
+
+func (in *PubSubTopicMetadataProvider) GetResourceID(projectInfo *resourcemanagerpb.Project, r *pubsubv1beta1.PubSubTopic) string {
+	name := r.Name
+	if r.Spec.ResourceID != nil {
+		name = *r.Spec.ResourceID
+	}
+
+	return fmt.Sprintf("//pubsub.googleapis.com/projects/%s/topics/%s", projectInfo.ProjectId, name)
+}
+
+type PubSubSubscriptionMetadataProvider struct{}
+
+// GetResourceLocation returns the global location, subscriptions are global resources.
+func (in *PubSubSubscriptionMetadataProvider) GetResourceLocation(_ *pubsubv1beta1.PubSubSubscription) string {
+	return controller.GlobalLocation
+}
+
+func (in *PubSubSubscriptionMetadataProvider) GetResourceID(projectInfo *resourcemanagerpb.Project, r *pubsubv1beta1.PubSubSubscription) string {
+	name := r.Name
+	if r.Spec.ResourceID != nil {
+		name = *r.Spec.ResourceID
+	}
+
+	return fmt.Sprintf("//pubsub.googleapis.com/projects/%s/subscriptions/%s", projectInfo.ProjectId, name)
+}
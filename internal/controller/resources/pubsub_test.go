@@ -0,0 +1,141 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"testing"
+
+	"cloud.google.com/go/resourcemanager/apiv3/resourcemanagerpb"
+	pubsubv1beta1 "github.com/GoogleCloudPlatform/k8s-config-connector/pkg/clients/generated/apis/pubsub/v1beta1"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+
+	"github.com/deliveryhero/gcp-config-connector-tagging-operator/internal/controller"
+)
+
+func TestPubSubTopicMetadataProvider_GetResourceID(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name        string
+		r           *pubsubv1beta1.PubSubTopic
+		projectInfo *resourcemanagerpb.Project
+		want        string
+	}{
+		{
+			name: "with generated name",
+			r: &pubsubv1beta1.PubSubTopic{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "test-topic",
+				},
+			},
+			projectInfo: &resourcemanagerpb.Project{
+				ProjectId: "test-project",
+			},
+			want: "//pubsub.googleapis.com/projects/test-project/topics/test-topic",
+		},
+		{
+			name: "with overridden resource id",
+			r: &pubsubv1beta1.PubSubTopic{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "test-topic",
+				},
+				Spec: pubsubv1beta1.PubSubTopicSpec{
+					ResourceID: ptr.To("overridden-topic-id"),
+				},
+			},
+			projectInfo: &resourcemanagerpb.Project{
+				ProjectId: "test-project",
+			},
+			want: "//pubsub.googleapis.com/projects/test-project/topics/overridden-topic-id",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			p := &PubSubTopicMetadataProvider{}
+
+			got := p.GetResourceID(tc.projectInfo, tc.r)
+
+			require.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestPubSubTopicMetadataProvider_GetResourceLocation(t *testing.T) {
+	t.Parallel()
+
+	p := &PubSubTopicMetadataProvider{}
+	require.Equal(t, controller.GlobalLocation, p.GetResourceLocation(&pubsubv1beta1.PubSubTopic{}))
+}
+
+func TestPubSubSubscriptionMetadataProvider_GetResourceID(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name        string
+		r           *pubsubv1beta1.PubSubSubscription
+		projectInfo *resourcemanagerpb.Project
+		want        string
+	}{
+		{
+			name: "with generated name",
+			r: &pubsubv1beta1.PubSubSubscription{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "test-subscription",
+				},
+			},
+			projectInfo: &resourcemanagerpb.Project{
+				ProjectId: "test-project",
+			},
+			want: "//pubsub.googleapis.com/projects/test-project/subscriptions/test-subscription",
+		},
+		{
+			name: "with overridden resource id",
+			r: &pubsubv1beta1.PubSubSubscription{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "test-subscription",
+				},
+				Spec: pubsubv1beta1.PubSubSubscriptionSpec{
+					ResourceID: ptr.To("overridden-subscription-id"),
+				},
+			},
+			projectInfo: &resourcemanagerpb.Project{
+				ProjectId: "test-project",
+			},
+			want: "//pubsub.googleapis.com/projects/test-project/subscriptions/overridden-subscription-id",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			p := &PubSubSubscriptionMetadataProvider{}
+
+			got := p.GetResourceID(tc.projectInfo, tc.r)
+
+			require.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestPubSubSubscriptionMetadataProvider_GetResourceLocation(t *testing.T) {
+	t.Parallel()
+
+	p := &PubSubSubscriptionMetadataProvider{}
+	require.Equal(t, controller.GlobalLocation, p.GetResourceLocation(&pubsubv1beta1.PubSubSubscription{}))
+}
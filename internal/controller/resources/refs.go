@@ -0,0 +1,51 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"context"
+	"strings"
+
+	ccv1alpha1 "github.com/GoogleCloudPlatform/k8s-config-connector/pkg/clients/generated/apis/k8s/v1alpha1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/deliveryhero/gcp-config-connector-tagging-operator/internal/controller"
+)
+
+// resourceRefID returns the project and ID of a referenced resource, like the dataset of a table. External references
+// are either the ID or a relative resource name like "projects/<project>/datasets/<dataset>". Resources referenced by
+// name are assumed in projectID and to be named after their ID, resolveResourceRef reads them instead.
+func resourceRefID(projectID string, ref ccv1alpha1.ResourceRef) (string, string) {
+	if ref.External == "" {
+		return projectID, ref.Name
+	}
+	parts := strings.Split(ref.External, "/")
+	if len(parts) >= 4 && parts[0] == "projects" {
+		return parts[1], parts[len(parts)-1]
+	}
+	return projectID, ref.External
+}
+
+// resolveResourceRef returns the project and ID of a referenced resource like resourceRefID, but reads resources
+// referenced by name into obj to use their spec.resourceID and project. It returns false while they do not exist.
+func resolveResourceRef(ctx context.Context, resolver *controller.ReferenceResolver, namespace, projectID string, ref ccv1alpha1.ResourceRef, obj client.Object) (string, string, bool, error) {
+	if ref.External != "" {
+		refProjectID, id := resourceRefID(projectID, ref)
+		return refProjectID, id, true, nil
+	}
+	return resolver.Resolve(ctx, namespace, ref, obj)
+}
@@ -0,0 +1,105 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"context"
+	"testing"
+
+	bigqueryv1beta1 "github.com/GoogleCloudPlatform/k8s-config-connector/pkg/clients/generated/apis/bigquery/v1beta1"
+	containerv1beta1 "github.com/GoogleCloudPlatform/k8s-config-connector/pkg/clients/generated/apis/container/v1beta1"
+	ccv1alpha1 "github.com/GoogleCloudPlatform/k8s-config-connector/pkg/clients/generated/apis/k8s/v1alpha1"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/deliveryhero/gcp-config-connector-tagging-operator/internal/controller"
+)
+
+func newTestReferenceResolver(t *testing.T, objs ...client.Object) *controller.ReferenceResolver {
+	scheme := runtime.NewScheme()
+	require.NoError(t, corev1.AddToScheme(scheme))
+	require.NoError(t, bigqueryv1beta1.AddToScheme(scheme))
+	require.NoError(t, containerv1beta1.AddToScheme(scheme))
+
+	return controller.NewReferenceResolver(fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).Build())
+}
+
+func TestResolveResourceRef(t *testing.T) {
+	t.Parallel()
+
+	resolver := newTestReferenceResolver(t,
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+			Name:        "default",
+			Annotations: map[string]string{"cnrm.cloud.google.com/project-id": "namespace-project"},
+		}},
+		&bigqueryv1beta1.BigQueryDataset{ObjectMeta: metav1.ObjectMeta{Name: "named-dataset", Namespace: "default"}},
+		&bigqueryv1beta1.BigQueryDataset{
+			ObjectMeta: metav1.ObjectMeta{Name: "other-project-dataset", Namespace: "default"},
+			Spec: bigqueryv1beta1.BigQueryDatasetSpec{
+				ProjectRef: &ccv1alpha1.ResourceRef{External: "projects/other-project"},
+			},
+		},
+	)
+
+	testCases := []struct {
+		name          string
+		ref           ccv1alpha1.ResourceRef
+		wantProjectID string
+		wantID        string
+		wantResolved  bool
+	}{
+		{
+			name:          "external ID",
+			ref:           ccv1alpha1.ResourceRef{External: "external_dataset"},
+			wantProjectID: "test-project", wantID: "external_dataset", wantResolved: true,
+		},
+		{
+			name:          "external relative resource name",
+			ref:           ccv1alpha1.ResourceRef{External: "projects/other-project/datasets/external_dataset"},
+			wantProjectID: "other-project", wantID: "external_dataset", wantResolved: true,
+		},
+		{
+			name:          "name in the project of the namespace",
+			ref:           ccv1alpha1.ResourceRef{Name: "named-dataset"},
+			wantProjectID: "namespace-project", wantID: "named-dataset", wantResolved: true,
+		},
+		{
+			name:          "name with project reference",
+			ref:           ccv1alpha1.ResourceRef{Name: "other-project-dataset"},
+			wantProjectID: "other-project", wantID: "other-project-dataset", wantResolved: true,
+		},
+		{
+			name: "missing resource",
+			ref:  ccv1alpha1.ResourceRef{Name: "missing-dataset"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			projectID, id, resolved, err := resolveResourceRef(context.Background(), resolver, "default", "test-project", tc.ref, &bigqueryv1beta1.BigQueryDataset{})
+
+			require.NoError(t, err)
+			require.Equal(t, tc.wantResolved, resolved)
+			require.Equal(t, tc.wantProjectID, projectID)
+			require.Equal(t, tc.wantID, id)
+		})
+	}
+}
@@ -0,0 +1,49 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"fmt"
+	"strings"
+
+	"cloud.google.com/go/resourcemanager/apiv3/resourcemanagerpb"
+	secretmanagerv1beta1 "github.com/GoogleCloudPlatform/k8s-config-connector/pkg/clients/generated/apis/secretmanager/v1beta1"
+
+	"github.com/deliveryhero/gcp-config-connector-tagging-operator/internal/controller"
+)
+
+// +kubebuilder:rbac:groups=secretmanager.cnrm.cloud.google.com,resources=secretmanagersecrets,verbs=get;list;watch;update
+
+var _ controller.ResourceMetadataProvider[secretmanagerv1beta1.SecretManagerSecret] = &SecretManagerSecretMetadataProvider{}
+
+type SecretManagerSecretMetadataProvider struct{}
+
+// GetResourceLocation returns the global location, secrets are global resources even if their replicas are not.
+func (in *SecretManagerSecretMetadataProvider) GetResourceLocation(_ *secretmanagerv1beta1.SecretManagerSecret) string {
+	return controller.GlobalLocation
+}
+
+func (in *SecretManagerSecretMetadataProvider) GetResourceID(projectInfo *resourcemanagerpb.Project, r *secretmanagerv1beta1.SecretManagerSecret) string {
+	name := r.Name
+	if r.Spec.ResourceID != nil {
+		name = *r.Spec.ResourceID
+	}
+
+	projectNumber := strings.TrimPrefix(projectInfo.Name, "projects/")
+
+	return fmt.Sprintf("//secretmanager.googleapis.com/projects/%s/secrets/%s", projectNumber, name)
+}
@@ -0,0 +1,87 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"testing"
+
+	"cloud.google.com/go/resourcemanager/apiv3/resourcemanagerpb"
+	secretmanagerv1beta1 "github.com/GoogleCloudPlatform/k8s-config-connector/pkg/clients/generated/apis/secretmanager/v1beta1"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+
+	"github.com/deliveryhero/gcp-config-connector-tagging-operator/internal/controller"
+)
+
+func TestSecretManagerSecretMetadataProvider_GetResourceID(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name        string
+		r           *secretmanagerv1beta1.SecretManagerSecret
+		projectInfo *resourcemanagerpb.Project
+		want        string
+	}{
+		{
+			name: "with generated name",
+			r: &secretmanagerv1beta1.SecretManagerSecret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "test-secret",
+				},
+			},
+			projectInfo: &resourcemanagerpb.Project{
+				Name:      "projects/123456",
+				ProjectId: "test-project",
+			},
+			want: "//secretmanager.googleapis.com/projects/123456/secrets/test-secret",
+		},
+		{
+			name: "with overridden resource id",
+			r: &secretmanagerv1beta1.SecretManagerSecret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "test-secret",
+				},
+				Spec: secretmanagerv1beta1.SecretManagerSecretSpec{
+					ResourceID: ptr.To("overridden-secret-id"),
+				},
+			},
+			projectInfo: &resourcemanagerpb.Project{
+				Name:      "projects/123456",
+				ProjectId: "test-project",
+			},
+			want: "//secretmanager.googleapis.com/projects/123456/secrets/overridden-secret-id",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			p := &SecretManagerSecretMetadataProvider{}
+
+			got := p.GetResourceID(tc.projectInfo, tc.r)
+
+			require.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestSecretManagerSecretMetadataProvider_GetResourceLocation(t *testing.T) {
+	t.Parallel()
+
+	p := &SecretManagerSecretMetadataProvider{}
+	require.Equal(t, controller.GlobalLocation, p.GetResourceLocation(&secretmanagerv1beta1.SecretManagerSecret{}))
+}
@@ -0,0 +1,135 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	taggingv1alpha1 "github.com/deliveryhero/gcp-config-connector-tagging-operator/api/v1alpha1"
+)
+
+const (
+	reasonTagsBound          = "TagsBound"
+	reasonTagBindingsPending = "TagBindingsPending"
+	reasonTagCreationPending = "TagCreationPending"
+	reasonReconcileError     = "ReconcileError"
+)
+
+// updateTagAssignment writes the outcome of a reconciliation to the TagAssignment owned by the resource.
+func (r *TaggableResourceReconciler[T, P, PT]) updateTagAssignment(ctx context.Context, resource PT, status *taggingv1alpha1.TagAssignmentStatus, reconcileErr error) error {
+	gvk := resource.GetObjectKind().GroupVersionKind()
+	assignment := &taggingv1alpha1.TagAssignment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      tagAssignmentName(resource),
+			Namespace: resource.GetNamespace(),
+		},
+	}
+
+	if _, err := controllerutil.CreateOrUpdate(ctx, r.Client, assignment, func() error {
+		assignment.Spec.ResourceRef = taggingv1alpha1.ResourceReference{
+			APIVersion: gvk.GroupVersion().String(),
+			Kind:       gvk.Kind,
+			Name:       resource.GetName(),
+		}
+		return ctrl.SetControllerReference(resource, assignment, r.Scheme)
+	}); err != nil {
+		return fmt.Errorf("failed to create or update tag assignment: %w", err)
+	}
+
+	// keep the existing conditions, so transition times only change on actual transitions
+	status.Conditions = assignment.Status.Conditions
+	setTagAssignmentConditions(status, reconcileErr)
+	if equality.Semantic.DeepEqual(assignment.Status, *status) {
+		return nil
+	}
+
+	assignment.Status = *status
+	if err := r.Status().Update(ctx, assignment); err != nil {
+		// in dry-run mode a new TagAssignment is never persisted, so the API server cannot find it to update its status
+		if r.DryRun != nil && errors.IsNotFound(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to update tag assignment status: %w", err)
+	}
+	return nil
+}
+
+func setTagAssignmentConditions(status *taggingv1alpha1.TagAssignmentStatus, reconcileErr error) {
+	if reconcileErr != nil {
+		status.LastError = reconcileErr.Error()
+		setCondition(status, taggingv1alpha1.ConditionReady, metav1.ConditionFalse, reasonReconcileError, reconcileErr.Error())
+		setCondition(status, taggingv1alpha1.ConditionProgressing, metav1.ConditionFalse, reasonReconcileError, "")
+		setCondition(status, taggingv1alpha1.ConditionDegraded, metav1.ConditionTrue, reasonReconcileError, reconcileErr.Error())
+		return
+	}
+
+	var pending []string
+	for _, tag := range status.Tags {
+		if !tag.Ready {
+			pending = append(pending, tag.BindingName)
+		}
+	}
+
+	setCondition(status, taggingv1alpha1.ConditionDegraded, metav1.ConditionFalse, reasonTagsBound, "")
+	if len(status.PendingOperations) > 0 {
+		var operations []string
+		for _, op := range status.PendingOperations {
+			operations = append(operations, op.Operation)
+		}
+		message := fmt.Sprintf("Waiting for the creation of tags: %s", strings.Join(operations, ", "))
+		setCondition(status, taggingv1alpha1.ConditionReady, metav1.ConditionFalse, reasonTagCreationPending, message)
+		setCondition(status, taggingv1alpha1.ConditionProgressing, metav1.ConditionTrue, reasonTagCreationPending, message)
+		return
+	}
+	if len(pending) > 0 {
+		message := fmt.Sprintf("Waiting for tag bindings: %s", strings.Join(pending, ", "))
+		setCondition(status, taggingv1alpha1.ConditionReady, metav1.ConditionFalse, reasonTagBindingsPending, message)
+		setCondition(status, taggingv1alpha1.ConditionProgressing, metav1.ConditionTrue, reasonTagBindingsPending, message)
+		return
+	}
+	setCondition(status, taggingv1alpha1.ConditionReady, metav1.ConditionTrue, reasonTagsBound, "All tag bindings are applied")
+	setCondition(status, taggingv1alpha1.ConditionProgressing, metav1.ConditionFalse, reasonTagsBound, "")
+}
+
+func setCondition(status *taggingv1alpha1.TagAssignmentStatus, conditionType string, conditionStatus metav1.ConditionStatus, reason, message string) {
+	meta.SetStatusCondition(&status.Conditions, metav1.Condition{
+		Type:               conditionType,
+		Status:             conditionStatus,
+		Reason:             reason,
+		Message:            message,
+		ObservedGeneration: status.ObservedGeneration,
+	})
+}
+
+func tagAssignmentName(owner client.Object) string {
+	kind := strings.ToLower(owner.GetObjectKind().GroupVersionKind().Kind)
+	name := fmt.Sprintf("%s-%s", kind, owner.GetName())
+	if len(name) > 253 {
+		name = name[:253]
+	}
+	return name
+}
@@ -0,0 +1,340 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	ccv1alpha1 "github.com/GoogleCloudPlatform/k8s-config-connector/pkg/clients/generated/apis/k8s/v1alpha1"
+	storagev1beta1 "github.com/GoogleCloudPlatform/k8s-config-connector/pkg/clients/generated/apis/storage/v1beta1"
+	tagsv1alpha1 "github.com/GoogleCloudPlatform/k8s-config-connector/pkg/clients/generated/apis/tags/v1alpha1"
+	tagsv1beta1 "github.com/GoogleCloudPlatform/k8s-config-connector/pkg/clients/generated/apis/tags/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	taggingv1alpha1 "github.com/deliveryhero/gcp-config-connector-tagging-operator/api/v1alpha1"
+	"github.com/deliveryhero/gcp-config-connector-tagging-operator/internal/policy"
+	"github.com/deliveryhero/gcp-config-connector-tagging-operator/internal/util"
+)
+
+type testBucketReconciler = TaggableResourceReconciler[storagev1beta1.StorageBucket, *testBucketMetadataProvider, *storagev1beta1.StorageBucket]
+
+// newTestBucketReconciler creates a reconciler for StorageBuckets backed by a fake client.
+func newTestBucketReconciler(tagsManager *fakeTagsManager, objs ...client.Object) *testBucketReconciler {
+	scheme := runtime.NewScheme()
+	Expect(corev1.AddToScheme(scheme)).To(Succeed())
+	Expect(storagev1beta1.AddToScheme(scheme)).To(Succeed())
+	Expect(tagsv1alpha1.AddToScheme(scheme)).To(Succeed())
+	Expect(tagsv1beta1.AddToScheme(scheme)).To(Succeed())
+	Expect(taggingv1alpha1.AddToScheme(scheme)).To(Succeed())
+
+	k8sClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(objs...).
+		WithStatusSubresource(&taggingv1alpha1.TagAssignment{}).
+		WithIndex(&tagsv1alpha1.TagsLocationTagBinding{}, tagBindingOwnerKey, indexTagBindingOwner).
+		WithIndex(&tagsv1beta1.TagsTagBinding{}, tagBindingOwnerKey, indexTagBindingOwner).
+		Build()
+
+	labelMatcher, err := util.LimitLabelsWithRegex(".*")
+	Expect(err).NotTo(HaveOccurred())
+
+	return &testBucketReconciler{
+		Client:           k8sClient,
+		Scheme:           scheme,
+		TagsManager:      tagsManager,
+		MetadataProvider: &testBucketMetadataProvider{},
+		TagEvaluator:     policy.NewEvaluator(k8sClient, labelMatcher),
+		Recorder:         record.NewFakeRecorder(100),
+	}
+}
+
+var _ = Describe("Tag Assignment", func() {
+	var (
+		ctx         context.Context
+		tagsManager *fakeTagsManager
+		reconciler  *testBucketReconciler
+		request     ctrl.Request
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		tagsManager = &fakeTagsManager{}
+		bucket := &storagev1beta1.StorageBucket{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "test-bucket",
+				Namespace: "default",
+				Labels:    map[string]string{"team": "payments"},
+			},
+			Spec: storagev1beta1.StorageBucketSpec{Location: ptr.To("EU")},
+		}
+		namespace := &corev1.Namespace{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "default",
+				Annotations: map[string]string{projectIDAnnotation: "test-project"},
+			},
+		}
+		reconciler = newTestBucketReconciler(tagsManager, bucket, namespace)
+		request = ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "test-bucket"}}
+	})
+
+	getAssignment := func() *taggingv1alpha1.TagAssignment {
+		var assignment taggingv1alpha1.TagAssignment
+		Expect(reconciler.Get(ctx, types.NamespacedName{Namespace: "default", Name: "storagebucket-test-bucket"}, &assignment)).To(Succeed())
+		return &assignment
+	}
+
+	It("should report pending bindings until Config Connector applied them", func() {
+		_, err := reconciler.Reconcile(ctx, request)
+		Expect(err).NotTo(HaveOccurred())
+
+		assignment := getAssignment()
+		Expect(assignment.Spec.ResourceRef).To(Equal(taggingv1alpha1.ResourceReference{
+			APIVersion: "storage.cnrm.cloud.google.com/v1beta1",
+			Kind:       "StorageBucket",
+			Name:       "test-bucket",
+		}))
+		Expect(assignment.OwnerReferences).To(HaveLen(1))
+		Expect(assignment.Status.ProjectID).To(Equal("test-project"))
+		Expect(assignment.Status.Tags).To(Equal([]taggingv1alpha1.TagStatus{{
+			Key:            "team",
+			Value:          "payments",
+			TagValue:       "tagValues/team-payments",
+			NamespacedName: "test-project/team/payments",
+			BindingName:    "storagebucket-test-bucket-team-payments",
+			Ready:          false,
+		}}))
+		Expect(meta.IsStatusConditionFalse(assignment.Status.Conditions, taggingv1alpha1.ConditionReady)).To(BeTrue())
+		Expect(meta.IsStatusConditionTrue(assignment.Status.Conditions, taggingv1alpha1.ConditionProgressing)).To(BeTrue())
+
+		var binding tagsv1alpha1.TagsLocationTagBinding
+		Expect(reconciler.Get(ctx, types.NamespacedName{Namespace: "default", Name: "storagebucket-test-bucket-team-payments"}, &binding)).To(Succeed())
+		binding.Status.Conditions = []ccv1alpha1.Condition{{Type: "Ready", Status: corev1.ConditionTrue}}
+		Expect(reconciler.Update(ctx, &binding)).To(Succeed())
+
+		_, err = reconciler.Reconcile(ctx, request)
+		Expect(err).NotTo(HaveOccurred())
+
+		assignment = getAssignment()
+		Expect(assignment.Status.Tags[0].Ready).To(BeTrue())
+		Expect(meta.IsStatusConditionTrue(assignment.Status.Conditions, taggingv1alpha1.ConditionReady)).To(BeTrue())
+		Expect(meta.IsStatusConditionFalse(assignment.Status.Conditions, taggingv1alpha1.ConditionProgressing)).To(BeTrue())
+	})
+
+	It("should requeue instead of waiting for the creation of tag values", func() {
+		tagsManager.pendingValues = map[string]string{"team/payments": "operations/create-value"}
+		tagsManager.finishedOperations = map[string]bool{}
+
+		result, err := reconciler.Reconcile(ctx, request)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.RequeueAfter).To(Equal(pendingOperationPollInterval))
+
+		assignment := getAssignment()
+		Expect(assignment.Status.PendingOperations).To(Equal([]taggingv1alpha1.PendingOperation{{
+			Operation: "operations/create-value",
+			Kind:      "TagValue",
+			TagKey:    "team",
+			TagValue:  "payments",
+		}}))
+		Expect(assignment.Status.Tags).To(HaveLen(1))
+		Expect(assignment.Status.Tags[0].TagValue).To(BeEmpty())
+		ready := meta.FindStatusCondition(assignment.Status.Conditions, taggingv1alpha1.ConditionReady)
+		Expect(ready.Status).To(Equal(metav1.ConditionFalse))
+		Expect(ready.Reason).To(Equal(reasonTagCreationPending))
+
+		// the recorded operation is polled, without looking up the tag value again
+		result, err = reconciler.Reconcile(ctx, request)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.RequeueAfter).To(Equal(pendingOperationPollInterval))
+		Expect(tagsManager.checkedOperations).To(Equal([]string{"operations/create-value"}))
+		Expect(tagsManager.ensured).To(Equal(1))
+
+		tagsManager.finishedOperations["operations/create-value"] = true
+		result, err = reconciler.Reconcile(ctx, request)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.RequeueAfter).To(BeZero())
+
+		assignment = getAssignment()
+		Expect(assignment.Status.PendingOperations).To(BeEmpty())
+		Expect(assignment.Status.Tags[0].BindingName).To(Equal("storagebucket-test-bucket-team-payments"))
+	})
+
+	It("should report labels which cannot be mapped to tags as terminal errors", func() {
+		var bucket storagev1beta1.StorageBucket
+		Expect(reconciler.Get(ctx, request.NamespacedName, &bucket)).To(Succeed())
+		bucket.Labels["app.kubernetes.io/name"] = "api"
+		Expect(reconciler.Update(ctx, &bucket)).To(Succeed())
+
+		_, err := reconciler.Reconcile(ctx, request)
+		Expect(errors.Is(err, reconcile.TerminalError(nil))).To(BeTrue())
+
+		assignment := getAssignment()
+		Expect(assignment.Status.Tags).To(HaveLen(1))
+		Expect(assignment.Status.LastError).To(ContainSubstring("app.kubernetes.io/name"))
+		Expect(meta.IsStatusConditionTrue(assignment.Status.Conditions, taggingv1alpha1.ConditionDegraded)).To(BeTrue())
+		events := reconciler.Recorder.(*record.FakeRecorder).Events
+		Expect(events).To(HaveLen(2))
+		<-events
+		Expect(<-events).To(ContainSubstring(EventReasonInvalidTag))
+	})
+
+	It("should keep requeueing degraded resources with pending operations", func() {
+		tagsManager.pendingValues = map[string]string{"team/payments": "operations/create-value"}
+		tagsManager.finishedOperations = map[string]bool{}
+		var bucket storagev1beta1.StorageBucket
+		Expect(reconciler.Get(ctx, request.NamespacedName, &bucket)).To(Succeed())
+		bucket.Labels["app.kubernetes.io/name"] = "api"
+		Expect(reconciler.Update(ctx, &bucket)).To(Succeed())
+
+		result, err := reconciler.Reconcile(ctx, request)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.RequeueAfter).To(Equal(pendingOperationPollInterval))
+
+		assignment := getAssignment()
+		Expect(assignment.Status.PendingOperations).To(HaveLen(1))
+		Expect(assignment.Status.LastError).To(ContainSubstring("app.kubernetes.io/name"))
+		Expect(meta.IsStatusConditionTrue(assignment.Status.Conditions, taggingv1alpha1.ConditionDegraded)).To(BeTrue())
+		Expect(<-reconciler.Recorder.(*record.FakeRecorder).Events).To(ContainSubstring(EventReasonInvalidTag))
+
+		// once the operation finished, nothing is left to requeue for
+		tagsManager.finishedOperations["operations/create-value"] = true
+		result, err = reconciler.Reconcile(ctx, request)
+		Expect(errors.Is(err, reconcile.TerminalError(nil))).To(BeTrue())
+		Expect(result.RequeueAfter).To(BeZero())
+	})
+
+	It("should bind global resources with TagsTagBindings", func() {
+		reconciler.MetadataProvider = &testBucketMetadataProvider{location: GlobalLocation}
+
+		_, err := reconciler.Reconcile(ctx, request)
+		Expect(err).NotTo(HaveOccurred())
+
+		var locationBindings tagsv1alpha1.TagsLocationTagBindingList
+		Expect(reconciler.List(ctx, &locationBindings)).To(Succeed())
+		Expect(locationBindings.Items).To(BeEmpty())
+		var bindings tagsv1beta1.TagsTagBindingList
+		Expect(reconciler.List(ctx, &bindings)).To(Succeed())
+		Expect(bindings.Items).To(HaveLen(1))
+		Expect(bindings.Items[0].Name).To(Equal("storagebucket-test-bucket-team-payments"))
+		Expect(bindings.Items[0].Spec.TagValueRef.External).To(Equal("tagValues/team-payments"))
+
+		// a second pass finds the binding unchanged
+		_, err = reconciler.Reconcile(ctx, request)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(getAssignment().Status.Tags[0].BindingName).To(Equal("storagebucket-test-bucket-team-payments"))
+		Expect(reconciler.List(ctx, &bindings)).To(Succeed())
+		Expect(bindings.Items).To(HaveLen(1))
+	})
+
+	It("should requeue resources until their status is populated", func() {
+		reconciler.MetadataProvider = &testBucketMetadataProvider{statusPending: true}
+
+		result, err := reconciler.Reconcile(ctx, request)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.RequeueAfter).To(Equal(resourceStatusPollInterval))
+
+		var bindings tagsv1alpha1.TagsLocationTagBindingList
+		Expect(reconciler.List(ctx, &bindings)).To(Succeed())
+		Expect(bindings.Items).To(BeEmpty())
+
+		reconciler.MetadataProvider = &testBucketMetadataProvider{}
+		_, err = reconciler.Reconcile(ctx, request)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(reconciler.List(ctx, &bindings)).To(Succeed())
+		Expect(bindings.Items).To(HaveLen(1))
+	})
+
+	It("should not bind secure tags to resources not supporting them", func() {
+		tagsManager.firewallNetworks = map[string]string{"team": "test-project/prod"}
+
+		_, err := reconciler.Reconcile(ctx, request)
+		Expect(errors.Is(err, reconcile.TerminalError(nil))).To(BeTrue())
+		Expect(err.Error()).To(ContainSubstring("secure tags cannot be bound to StorageBucket"))
+
+		var bindings tagsv1alpha1.TagsLocationTagBindingList
+		Expect(reconciler.List(ctx, &bindings)).To(Succeed())
+		Expect(bindings.Items).To(BeEmpty())
+		Expect(tagsManager.ensured).To(BeZero())
+	})
+
+	It("should only bind secure tags of the network of the resource", func() {
+		reconciler.MetadataProvider = &testBucketMetadataProvider{firewallTags: true}
+		reconciler.FirewallNetworkLabel = "network"
+		tagsManager.firewallNetworks = map[string]string{"team": "test-project/dev", "role": "test-project/prod"}
+		var bucket storagev1beta1.StorageBucket
+		Expect(reconciler.Get(ctx, request.NamespacedName, &bucket)).To(Succeed())
+		bucket.Labels["network"] = "prod"
+		bucket.Labels["role"] = "web"
+		Expect(reconciler.Update(ctx, &bucket)).To(Succeed())
+
+		_, err := reconciler.Reconcile(ctx, request)
+		Expect(errors.Is(err, reconcile.TerminalError(nil))).To(BeTrue())
+		Expect(err.Error()).To(ContainSubstring("team (secure tag of network test-project/dev cannot be bound to a resource in network test-project/prod)"))
+
+		var bindings tagsv1alpha1.TagsLocationTagBindingList
+		Expect(reconciler.List(ctx, &bindings)).To(Succeed())
+		var names []string
+		for _, binding := range bindings.Items {
+			names = append(names, binding.Name)
+		}
+		Expect(names).To(ConsistOf("storagebucket-test-bucket-network-prod", "storagebucket-test-bucket-role-web"))
+	})
+
+	It("should read the Config Connector service account of the namespace", func() {
+		cc := &unstructured.Unstructured{}
+		cc.SetGroupVersionKind(configConnectorContextGVK)
+		cc.SetNamespace("default")
+		cc.SetName(configConnectorContextName)
+		Expect(unstructured.SetNestedField(cc.Object, "cnrm-default@test-project.iam.gserviceaccount.com", "spec", "googleServiceAccount")).To(Succeed())
+		Expect(reconciler.Create(ctx, cc)).To(Succeed())
+
+		serviceAccount, err := reconciler.configConnectorServiceAccount(ctx, "default")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(serviceAccount).To(Equal("cnrm-default@test-project.iam.gserviceaccount.com"))
+
+		serviceAccount, err = reconciler.configConnectorServiceAccount(ctx, "other")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(serviceAccount).To(BeEmpty())
+	})
+
+	It("should report reconciliation errors", func() {
+		tagsManager.lookupErr = fmt.Errorf("permission denied")
+
+		_, err := reconciler.Reconcile(ctx, request)
+		Expect(err).To(HaveOccurred())
+
+		assignment := getAssignment()
+		Expect(assignment.Status.LastError).To(Equal("permission denied"))
+		Expect(meta.IsStatusConditionTrue(assignment.Status.Conditions, taggingv1alpha1.ConditionDegraded)).To(BeTrue())
+		Expect(meta.IsStatusConditionFalse(assignment.Status.Conditions, taggingv1alpha1.ConditionReady)).To(BeTrue())
+	})
+})
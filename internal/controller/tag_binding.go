@@ -0,0 +1,90 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"strings"
+
+	ccv1alpha1 "github.com/GoogleCloudPlatform/k8s-config-connector/pkg/clients/generated/apis/k8s/v1alpha1"
+	tagsv1alpha1 "github.com/GoogleCloudPlatform/k8s-config-connector/pkg/clients/generated/apis/tags/v1alpha1"
+	tagsv1beta1 "github.com/GoogleCloudPlatform/k8s-config-connector/pkg/clients/generated/apis/tags/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// GlobalLocation is the location metadata providers of global resources return. Tags are bound to global resources
+// with a TagsTagBinding, which has no location, instead of a TagsLocationTagBinding.
+const GlobalLocation = "global"
+
+func isGlobalLocation(location string) bool {
+	return strings.EqualFold(location, GlobalLocation)
+}
+
+// newTagBinding creates a TagsTagBinding for global resources and a TagsLocationTagBinding for all others.
+func newTagBinding(location string, parentRef ccv1alpha1.ResourceRef, tagValueRef ccv1alpha1.ResourceRef) client.Object {
+	if isGlobalLocation(location) {
+		return &tagsv1beta1.TagsTagBinding{
+			Spec: tagsv1beta1.TagsTagBindingSpec{ParentRef: parentRef, TagValueRef: tagValueRef},
+		}
+	}
+	return &tagsv1alpha1.TagsLocationTagBinding{
+		Spec: tagsv1alpha1.TagsLocationTagBindingSpec{Location: location, ParentRef: parentRef, TagValueRef: tagValueRef},
+	}
+}
+
+// tagBindingSpec returns the location, parent and tag value of a tag binding. TagsTagBindings are in the GlobalLocation.
+func tagBindingSpec(binding client.Object) (string, ccv1alpha1.ResourceRef, ccv1alpha1.ResourceRef) {
+	switch b := binding.(type) {
+	case *tagsv1alpha1.TagsLocationTagBinding:
+		return b.Spec.Location, b.Spec.ParentRef, b.Spec.TagValueRef
+	case *tagsv1beta1.TagsTagBinding:
+		return GlobalLocation, b.Spec.ParentRef, b.Spec.TagValueRef
+	}
+	return "", ccv1alpha1.ResourceRef{}, ccv1alpha1.ResourceRef{}
+}
+
+// tagBindingConditions returns the status conditions Config Connector reports for a tag binding.
+func tagBindingConditions(binding client.Object) []ccv1alpha1.Condition {
+	switch b := binding.(type) {
+	case *tagsv1alpha1.TagsLocationTagBinding:
+		return b.Status.Conditions
+	case *tagsv1beta1.TagsTagBinding:
+		return b.Status.Conditions
+	}
+	return nil
+}
+
+// listTagBindings lists the TagsLocationTagBindings and TagsTagBindings matching opts.
+func listTagBindings(ctx context.Context, c client.Reader, opts ...client.ListOption) ([]client.Object, error) {
+	var locationBindings tagsv1alpha1.TagsLocationTagBindingList
+	if err := c.List(ctx, &locationBindings, opts...); err != nil {
+		return nil, err
+	}
+	var globalBindings tagsv1beta1.TagsTagBindingList
+	if err := c.List(ctx, &globalBindings, opts...); err != nil {
+		return nil, err
+	}
+
+	bindings := make([]client.Object, 0, len(locationBindings.Items)+len(globalBindings.Items))
+	for i := range locationBindings.Items {
+		bindings = append(bindings, &locationBindings.Items[i])
+	}
+	for i := range globalBindings.Items {
+		bindings = append(bindings, &globalBindings.Items[i])
+	}
+	return bindings, nil
+}
@@ -0,0 +1,138 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+
+	taggingv1alpha1 "github.com/deliveryhero/gcp-config-connector-tagging-operator/api/v1alpha1"
+	"github.com/deliveryhero/gcp-config-connector-tagging-operator/internal/gcp"
+)
+
+// TagCacheWarmer loads the tag keys and values of all known parents into the cache of the TagsManager,
+// at startup and then periodically, so reconciliations rarely need to look up single tags.
+type TagCacheWarmer struct {
+	client.Client
+	TagsManager  gcp.TagsManager
+	TagEvaluator TagEvaluator
+	// Interval between two refreshes of the cache.
+	Interval time.Duration
+}
+
+var _ manager.LeaderElectionRunnable = &TagCacheWarmer{}
+
+// NeedLeaderElection makes sure the cache is warmed by the replica which reconciles.
+func (w *TagCacheWarmer) NeedLeaderElection() bool {
+	return true
+}
+
+// Start warms the cache right away and then on every interval, until the context is cancelled.
+func (w *TagCacheWarmer) Start(ctx context.Context) error {
+	log := log.FromContext(ctx).WithName("tag-cache-warmer")
+	ctx = ctrl.LoggerInto(ctx, log)
+
+	wait.JitterUntilWithContext(ctx, func(ctx context.Context) {
+		if err := w.Warm(ctx); err != nil {
+			log.Error(err, "warming the tag cache failed")
+		}
+	}, w.Interval, 0.1, false)
+	return nil
+}
+
+// Warm loads the tag keys managed by the operator and their values for all projects known from
+// tag bindings, namespaces and tag assignments.
+func (w *TagCacheWarmer) Warm(ctx context.Context) error {
+	log := log.FromContext(ctx)
+
+	projectIDs, err := knownProjectIDs(ctx, w)
+	if err != nil {
+		return err
+	}
+
+	include := func(key string) bool {
+		managed, err := w.TagEvaluator.ManagesTagKey(ctx, key)
+		// rather cache too much than miss a managed key
+		return managed || err != nil
+	}
+
+	var warmErr error
+	cached := 0
+	start := time.Now()
+	for _, parent := range w.TagsManager.TagParents(projectIDs...) {
+		n, err := w.TagsManager.WarmCache(ctx, parent, include)
+		cached += n
+		if err != nil {
+			log.Error(err, "failed to warm the tag cache", "parent", parent)
+			warmErr = err
+		}
+	}
+	log.Info("warmed the tag cache", "entries", cached, "duration", time.Since(start))
+	return warmErr
+}
+
+// knownProjectIDs returns the projects of all tag bindings, the projects annotated on namespaces and the projects
+// of all tagged resources, which TagAssignments record whether they are set by annotation or project reference.
+func knownProjectIDs(ctx context.Context, c client.Reader) ([]string, error) {
+	seen := make(map[string]bool)
+
+	bindings, err := listTagBindings(ctx, c)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tag bindings: %w", err)
+	}
+	for _, binding := range bindings {
+		if projectID := binding.GetAnnotations()[projectIDAnnotation]; projectID != "" {
+			seen[projectID] = true
+		}
+	}
+
+	var namespaces corev1.NamespaceList
+	if err := c.List(ctx, &namespaces); err != nil {
+		return nil, fmt.Errorf("failed to list namespaces: %w", err)
+	}
+	for _, namespace := range namespaces.Items {
+		if projectID := namespace.Annotations[projectIDAnnotation]; projectID != "" {
+			seen[projectID] = true
+		}
+	}
+
+	var assignments taggingv1alpha1.TagAssignmentList
+	if err := c.List(ctx, &assignments); err != nil {
+		return nil, fmt.Errorf("failed to list tag assignments: %w", err)
+	}
+	for _, assignment := range assignments.Items {
+		if projectID := assignment.Status.ProjectID; projectID != "" {
+			seen[projectID] = true
+		}
+	}
+
+	projectIDs := make([]string, 0, len(seen))
+	for projectID := range seen {
+		projectIDs = append(projectIDs, projectID)
+	}
+	sort.Strings(projectIDs)
+	return projectIDs, nil
+}
@@ -0,0 +1,78 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"cloud.google.com/go/resourcemanager/apiv3/resourcemanagerpb"
+	tagsv1alpha1 "github.com/GoogleCloudPlatform/k8s-config-connector/pkg/clients/generated/apis/tags/v1alpha1"
+	tagsv1beta1 "github.com/GoogleCloudPlatform/k8s-config-connector/pkg/clients/generated/apis/tags/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	taggingv1alpha1 "github.com/deliveryhero/gcp-config-connector-tagging-operator/api/v1alpha1"
+	"github.com/deliveryhero/gcp-config-connector-tagging-operator/internal/policy"
+	"github.com/deliveryhero/gcp-config-connector-tagging-operator/internal/util"
+)
+
+var _ = Describe("Tag Cache Warmer", func() {
+	It("should warm the managed tag keys of all known projects", func() {
+		tagsManager := &fakeTagsManager{
+			keys: map[string][]*resourcemanagerpb.TagKey{
+				"projects/binding-project": {
+					{Name: "tagKeys/1", ShortName: "team"},
+					{Name: "tagKeys/2", ShortName: "foreign"},
+				},
+			},
+		}
+
+		scheme := runtime.NewScheme()
+		Expect(corev1.AddToScheme(scheme)).To(Succeed())
+		Expect(tagsv1alpha1.AddToScheme(scheme)).To(Succeed())
+		Expect(tagsv1beta1.AddToScheme(scheme)).To(Succeed())
+		Expect(taggingv1alpha1.AddToScheme(scheme)).To(Succeed())
+		k8sClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+			&tagsv1alpha1.TagsLocationTagBinding{ObjectMeta: metav1.ObjectMeta{
+				Namespace:   "default",
+				Name:        "binding",
+				Annotations: map[string]string{projectIDAnnotation: "binding-project"},
+			}},
+			&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+				Name:        "team-a",
+				Annotations: map[string]string{projectIDAnnotation: "namespace-project"},
+			}},
+			&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "kube-system"}},
+		).Build()
+		teamOnly, err := util.LimitLabelsWithRegex("^team$")
+		Expect(err).NotTo(HaveOccurred())
+
+		warmer := &TagCacheWarmer{
+			Client:       k8sClient,
+			TagsManager:  tagsManager,
+			TagEvaluator: policy.NewEvaluator(k8sClient, teamOnly),
+		}
+		Expect(warmer.Warm(context.Background())).To(Succeed())
+		Expect(tagsManager.warmedParents).To(Equal([]string{"projects/binding-project", "projects/namespace-project"}))
+		Expect(tagsManager.warmedKeys).To(Equal([]string{"team"}))
+	})
+})
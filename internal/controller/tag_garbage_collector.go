@@ -0,0 +1,164 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"cloud.google.com/go/resourcemanager/apiv3/resourcemanagerpb"
+	"k8s.io/apimachinery/pkg/util/wait"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+
+	"github.com/deliveryhero/gcp-config-connector-tagging-operator/internal/gcp"
+)
+
+// TagGarbageCollector periodically deletes tag values and keys created by the operator
+// that are no longer referenced by any tag binding in the cluster.
+type TagGarbageCollector struct {
+	client.Client
+	TagsManager  gcp.TagsManager
+	TagEvaluator TagEvaluator
+	// Interval between two garbage collection runs.
+	Interval time.Duration
+	// GracePeriod protects recently created keys and values, which may not be bound yet.
+	GracePeriod time.Duration
+	// DryRun only logs what would be deleted.
+	DryRun bool
+
+	now func() time.Time
+}
+
+var _ manager.LeaderElectionRunnable = &TagGarbageCollector{}
+
+// NeedLeaderElection makes sure only the leader deletes tags.
+func (gc *TagGarbageCollector) NeedLeaderElection() bool {
+	return true
+}
+
+// Start runs the garbage collection loop until the context is cancelled.
+func (gc *TagGarbageCollector) Start(ctx context.Context) error {
+	log := log.FromContext(ctx).WithName("tag-garbage-collector")
+	ctx = ctrl.LoggerInto(ctx, log)
+
+	wait.JitterUntilWithContext(ctx, func(ctx context.Context) {
+		if err := gc.Collect(ctx); err != nil {
+			log.Error(err, "tag garbage collection failed")
+		}
+	}, gc.Interval, 0.1, false)
+	return nil
+}
+
+// Collect runs a single garbage collection pass.
+func (gc *TagGarbageCollector) Collect(ctx context.Context) error {
+	log := log.FromContext(ctx)
+
+	bindings, err := listTagBindings(ctx, gc)
+	if err != nil {
+		return fmt.Errorf("failed to list tag bindings: %w", err)
+	}
+
+	referenced := make(map[string]bool, len(bindings))
+	for _, binding := range bindings {
+		_, _, tagValueRef := tagBindingSpec(binding)
+		referenced[tagValueRef.External] = true
+	}
+
+	// projects are read from the cluster on every pass, so orphans are still found after the last binding
+	// of a project is gone, even after a restart
+	projectIDs, err := knownProjectIDs(ctx, gc)
+	if err != nil {
+		return err
+	}
+
+	for _, parent := range gc.TagsManager.TagParents(projectIDs...) {
+		keys, listErr := gc.TagsManager.ListKeys(ctx, parent)
+		if listErr != nil {
+			log.Error(listErr, "failed to list tag keys", "parent", parent)
+			err = listErr
+			continue
+		}
+		for _, key := range keys {
+			managed, managedErr := gc.TagEvaluator.ManagesTagKey(ctx, key.ShortName)
+			if managedErr != nil {
+				log.Error(managedErr, "failed to check whether the tag key is managed", "key", key.NamespacedName)
+				err = managedErr
+				continue
+			}
+			if !managed {
+				// not managed by the operator
+				continue
+			}
+			if collectErr := gc.collectKey(ctx, key, referenced); collectErr != nil {
+				log.Error(collectErr, "failed to collect tag key", "key", key.NamespacedName)
+				err = collectErr
+			}
+		}
+	}
+	return err
+}
+
+func (gc *TagGarbageCollector) collectKey(ctx context.Context, key *resourcemanagerpb.TagKey, referenced map[string]bool) error {
+	log := log.FromContext(ctx).WithValues("key", key.NamespacedName)
+
+	values, err := gc.TagsManager.ListValues(ctx, key.Name)
+	if err != nil {
+		return err
+	}
+
+	remaining := len(values)
+	for _, value := range values {
+		if referenced[value.Name] || !gcp.IsOwned(value.Description) || !gc.pastGracePeriod(value.CreateTime.AsTime()) {
+			continue
+		}
+		if gc.DryRun {
+			log.Info("dry-run: would delete unused tag value", "value", value.NamespacedName)
+			remaining--
+			continue
+		}
+		log.Info("deleting unused tag value", "value", value.NamespacedName)
+		deleted, err := gc.TagsManager.DeleteValueIfUnused(ctx, "", key.Name, value.Name)
+		if err != nil {
+			return err
+		}
+		if deleted {
+			remaining--
+		}
+	}
+
+	if remaining > 0 || !gcp.IsOwned(key.Description) || !gc.pastGracePeriod(key.CreateTime.AsTime()) {
+		return nil
+	}
+	if gc.DryRun {
+		log.Info("dry-run: would delete unused tag key")
+		return nil
+	}
+	log.Info("deleting unused tag key")
+	return gc.TagsManager.DeleteKeyIfUnused(ctx, "", key.Name)
+}
+
+func (gc *TagGarbageCollector) pastGracePeriod(created time.Time) bool {
+	now := time.Now
+	if gc.now != nil {
+		now = gc.now
+	}
+	return now().Sub(created) >= gc.GracePeriod
+}
@@ -0,0 +1,181 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"cloud.google.com/go/resourcemanager/apiv3/resourcemanagerpb"
+	ccv1alpha1 "github.com/GoogleCloudPlatform/k8s-config-connector/pkg/clients/generated/apis/k8s/v1alpha1"
+	tagsv1alpha1 "github.com/GoogleCloudPlatform/k8s-config-connector/pkg/clients/generated/apis/tags/v1alpha1"
+	tagsv1beta1 "github.com/GoogleCloudPlatform/k8s-config-connector/pkg/clients/generated/apis/tags/v1beta1"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	taggingv1alpha1 "github.com/deliveryhero/gcp-config-connector-tagging-operator/api/v1alpha1"
+	"github.com/deliveryhero/gcp-config-connector-tagging-operator/internal/gcp"
+	"github.com/deliveryhero/gcp-config-connector-tagging-operator/internal/policy"
+	"github.com/deliveryhero/gcp-config-connector-tagging-operator/internal/util"
+)
+
+// failingKeyEvaluator fails to tell whether one tag key is managed.
+type failingKeyEvaluator struct {
+	TagEvaluator
+	key string
+}
+
+func (e *failingKeyEvaluator) ManagesTagKey(ctx context.Context, key string) (bool, error) {
+	if key == e.key {
+		return false, errors.New("policies unavailable")
+	}
+	return e.TagEvaluator.ManagesTagKey(ctx, key)
+}
+
+var _ = Describe("Tag Garbage Collector", func() {
+	var (
+		ctx         context.Context
+		now         time.Time
+		tagsManager *fakeTagsManager
+		gc          *TagGarbageCollector
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		now = time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+		old := timestamppb.New(now.Add(-48 * time.Hour))
+		recent := timestamppb.New(now.Add(-time.Hour))
+
+		tagsManager = &fakeTagsManager{
+			keys: map[string][]*resourcemanagerpb.TagKey{
+				"projects/test-project": {
+					{Name: "tagKeys/1", ShortName: "team", Description: gcp.OwnershipMarker, CreateTime: old},
+					{Name: "tagKeys/2", ShortName: "env", Description: gcp.OwnershipMarker, CreateTime: old},
+					{Name: "tagKeys/3", ShortName: "foreign", Description: gcp.OwnershipMarker, CreateTime: old},
+				},
+			},
+			values: map[string][]*resourcemanagerpb.TagValue{
+				"tagKeys/1": {
+					{Name: "tagValues/11", ShortName: "payments", Description: gcp.OwnershipMarker, CreateTime: old},
+					{Name: "tagValues/12", ShortName: "search", Description: gcp.OwnershipMarker, CreateTime: old},
+					{Name: "tagValues/13", ShortName: "new", Description: gcp.OwnershipMarker, CreateTime: recent},
+				},
+				"tagKeys/2": {
+					{Name: "tagValues/21", ShortName: "dev", Description: gcp.OwnershipMarker, CreateTime: old},
+				},
+				"tagKeys/3": {
+					{Name: "tagValues/31", ShortName: "unmanaged", Description: gcp.OwnershipMarker, CreateTime: old},
+				},
+			},
+		}
+
+		scheme := runtime.NewScheme()
+		Expect(corev1.AddToScheme(scheme)).To(Succeed())
+		Expect(tagsv1alpha1.AddToScheme(scheme)).To(Succeed())
+		Expect(tagsv1beta1.AddToScheme(scheme)).To(Succeed())
+		Expect(taggingv1alpha1.AddToScheme(scheme)).To(Succeed())
+		k8sClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(&tagsv1alpha1.TagsLocationTagBinding{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "storagebucket-test-bucket-11",
+				Namespace:   "default",
+				Annotations: map[string]string{projectIDAnnotation: "test-project"},
+			},
+			Spec: tagsv1alpha1.TagsLocationTagBindingSpec{
+				TagValueRef: ccv1alpha1.ResourceRef{External: "tagValues/11"},
+			},
+		}).Build()
+
+		labelMatcher, err := util.LimitLabelsWithRegex("^(team|env)$")
+		Expect(err).NotTo(HaveOccurred())
+
+		gc = &TagGarbageCollector{
+			Client:       k8sClient,
+			TagsManager:  tagsManager,
+			TagEvaluator: policy.NewEvaluator(k8sClient, labelMatcher),
+			GracePeriod:  24 * time.Hour,
+			now:          func() time.Time { return now },
+		}
+	})
+
+	It("should delete unreferenced values and keys past the grace period", func() {
+		Expect(gc.Collect(ctx)).To(Succeed())
+		Expect(tagsManager.deletedValues).To(ConsistOf("tagValues/12", "tagValues/21"))
+		Expect(tagsManager.deletedKeys).To(ConsistOf("tagKeys/2"))
+	})
+
+	It("should not delete values and keys created outside the operator", func() {
+		tagsManager.values["tagKeys/2"][0].Description = "created by hand"
+		Expect(gc.Collect(ctx)).To(Succeed())
+		Expect(tagsManager.deletedValues).To(ConsistOf("tagValues/12"))
+		Expect(tagsManager.deletedKeys).To(BeEmpty())
+	})
+
+	It("should never delete keys created outside the operator", func() {
+		tagsManager.keys["projects/test-project"][1].Description = "created by hand"
+		tagsManager.values["tagKeys/2"][0].Description = "created by hand"
+		Expect(gc.Collect(ctx)).To(Succeed())
+		Expect(tagsManager.deletedValues).To(ConsistOf("tagValues/12"))
+		Expect(tagsManager.deletedKeys).To(BeEmpty())
+	})
+
+	It("should keep keys with values still bound in GCP", func() {
+		tagsManager.boundValues = map[string]bool{"tagValues/21": true}
+		Expect(gc.Collect(ctx)).To(Succeed())
+		Expect(tagsManager.deletedValues).To(ConsistOf("tagValues/12"))
+		Expect(tagsManager.deletedKeys).To(BeEmpty())
+	})
+
+	It("should find projects without tag bindings", func() {
+		tagsManager.keys = map[string][]*resourcemanagerpb.TagKey{
+			"projects/namespace-project":  tagsManager.keys["projects/test-project"][1:2],
+			"projects/assignment-project": tagsManager.keys["projects/test-project"][0:1],
+		}
+		Expect(gc.Create(ctx, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+			Name:        "team-a",
+			Annotations: map[string]string{projectIDAnnotation: "namespace-project"},
+		}})).To(Succeed())
+		Expect(gc.Create(ctx, &taggingv1alpha1.TagAssignment{
+			ObjectMeta: metav1.ObjectMeta{Name: "storagebucket-other", Namespace: "team-b"},
+			Status:     taggingv1alpha1.TagAssignmentStatus{ProjectID: "assignment-project"},
+		})).To(Succeed())
+
+		Expect(gc.Collect(ctx)).To(Succeed())
+		Expect(tagsManager.deletedValues).To(ConsistOf("tagValues/12", "tagValues/21"))
+		Expect(tagsManager.deletedKeys).To(ConsistOf("tagKeys/2"))
+	})
+
+	It("should continue with the next key if one cannot be checked", func() {
+		gc.TagEvaluator = &failingKeyEvaluator{TagEvaluator: gc.TagEvaluator, key: "team"}
+		Expect(gc.Collect(ctx)).To(MatchError("policies unavailable"))
+		Expect(tagsManager.deletedValues).To(ConsistOf("tagValues/21"))
+		Expect(tagsManager.deletedKeys).To(ConsistOf("tagKeys/2"))
+	})
+
+	It("should not delete anything in dry-run mode", func() {
+		gc.DryRun = true
+		Expect(gc.Collect(ctx)).To(Succeed())
+		Expect(tagsManager.deletedValues).To(BeEmpty())
+		Expect(tagsManager.deletedKeys).To(BeEmpty())
+	})
+})
@@ -21,31 +21,55 @@ import (
 	"fmt"
 	"os"
 	"strings"
+	"sync"
 	"time"
 
 	"cloud.google.com/go/resourcemanager/apiv3/resourcemanagerpb"
 	ccv1alpha1 "github.com/GoogleCloudPlatform/k8s-config-connector/pkg/clients/generated/apis/k8s/v1alpha1"
+	resourcemanagerv1beta1 "github.com/GoogleCloudPlatform/k8s-config-connector/pkg/clients/generated/apis/resourcemanager/v1beta1"
 	tagsv1alpha1 "github.com/GoogleCloudPlatform/k8s-config-connector/pkg/clients/generated/apis/tags/v1alpha1"
+	tagsv1beta1 "github.com/GoogleCloudPlatform/k8s-config-connector/pkg/clients/generated/apis/tags/v1beta1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
+	taggingv1alpha1 "github.com/deliveryhero/gcp-config-connector-tagging-operator/api/v1alpha1"
+	"github.com/deliveryhero/gcp-config-connector-tagging-operator/internal/dryrun"
 	"github.com/deliveryhero/gcp-config-connector-tagging-operator/internal/gcp"
+	"github.com/deliveryhero/gcp-config-connector-tagging-operator/internal/policy"
 )
 
 const (
 	projectIDAnnotation       = "cnrm.cloud.google.com/project-id"
 	tagBindingOwnerKey        = ".metadata.controller"
+	projectRefKey             = ".spec.projectRef"
 	taggableResourceFinalizer = "gdp.deliveryhero.io/resource-tags"
+	// configConnectorContextName is the name Config Connector requires for the ConfigConnectorContext of a namespace
+	configConnectorContextName = "configconnectorcontext.core.cnrm.cloud.google.com"
 )
 
+// resourceStatusPollInterval is how often resources are requeued until Config Connector populates their status.
+const resourceStatusPollInterval = 10 * time.Second
+
+var configConnectorContextGVK = schema.GroupVersionKind{Group: "core.cnrm.cloud.google.com", Version: "v1beta1", Kind: "ConfigConnectorContext"}
+
 var (
 	setupLog = ctrl.Log.WithName("setup")
 )
@@ -55,12 +79,53 @@ type ResourceMetadataProvider[R any] interface {
 	GetResourceID(projectInfo *resourcemanagerpb.Project, r *R) string
 }
 
+// FirewallTagsProvider is implemented by metadata providers of resources which accept secure tags,
+// the values of tag keys with the GCE_FIREWALL purpose. Secure tags are not bound to other resources.
+type FirewallTagsProvider interface {
+	SupportsFirewallTags() bool
+}
+
+// ResourceStatusProvider is implemented by metadata providers deriving the location or ID of a resource from its status,
+// which Config Connector populates once the resource exists. Tags are not bound before the status is populated.
+type ResourceStatusProvider[R any] interface {
+	HasResourceStatus(r *R) bool
+}
+
 type ResourcePointer[T any] interface {
 	*T
 	client.Object
 }
 
 // +kubebuilder:rbac:groups=tags.cnrm.cloud.google.com,resources=tagslocationtagbindings,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=tags.cnrm.cloud.google.com,resources=tagstagbindings,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=resourcemanager.cnrm.cloud.google.com,resources=projects,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=namespaces,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=events,verbs=create;patch
+// +kubebuilder:rbac:groups=core.cnrm.cloud.google.com,resources=configconnectorcontexts,verbs=get
+// +kubebuilder:rbac:groups=tagging.gdp.deliveryhero.io,resources=tagassignments,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=tagging.gdp.deliveryhero.io,resources=tagassignments/status,verbs=get;update;patch
+
+// ReconcilerOptions holds optional settings shared by all taggable resource controllers.
+type ReconcilerOptions struct {
+	// DriftCheckInterval enables periodic comparison with the tag bindings in GCP when non-zero.
+	DriftCheckInterval time.Duration
+	// RepairDrift re-triggers Config Connector reconciliation of bindings missing in GCP.
+	RepairDrift bool
+	// DryRun, when set, makes the controllers only record the changes they would make to the cluster.
+	DryRun *dryrun.Recorder
+	// FirewallNetworkLabel is the label holding the VPC network of a resource, which secure tags must belong to.
+	FirewallNetworkLabel string
+	// TagUserFromContext grants the tag user role to the service account of Config Connector in the namespace of
+	// each resource, read from its ConfigConnectorContext, when Config Connector runs in namespaced mode.
+	TagUserFromContext bool
+}
+
+// TagEvaluator decides which tags a resource should carry, see policy.Evaluator.
+type TagEvaluator interface {
+	Evaluate(ctx context.Context, resource client.Object) (*policy.Result, error)
+	ManagesTagKey(ctx context.Context, key string) (bool, error)
+	UsesNamespaces() bool
+}
 
 // TaggableResourceReconciler reconciles any Google Cloud Config Connector object that can be tagged
 type TaggableResourceReconciler[T any, P ResourceMetadataProvider[T], PT ResourcePointer[T]] struct {
@@ -68,7 +133,12 @@ type TaggableResourceReconciler[T any, P ResourceMetadataProvider[T], PT Resourc
 	Scheme           *runtime.Scheme
 	TagsManager      gcp.TagsManager
 	MetadataProvider P
-	LabelMatcher     func(map[string]string) map[string]string
+	TagEvaluator     TagEvaluator
+	Recorder         record.EventRecorder
+	ReconcilerOptions
+
+	// fallbackProjects maps the resources warned about a project ID fallback to the project they fell back to
+	fallbackProjects sync.Map
 }
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
@@ -82,8 +152,14 @@ func (r *TaggableResourceReconciler[T, P, PT]) Reconcile(ctx context.Context, re
 	resource := r.newPT()
 	if err := r.Get(ctx, req.NamespacedName, resource); err != nil {
 		log.Error(err, "unable to fetch resource")
+		if errors.IsNotFound(err) {
+			r.fallbackProjects.Delete(req.String())
+		}
 		return ctrl.Result{}, client.IgnoreNotFound(err)
 	}
+	if err := r.setGroupVersionKind(resource); err != nil {
+		return ctrl.Result{}, err
+	}
 
 	// Handle TagBinding deletion using finalizer
 	if !resource.GetDeletionTimestamp().IsZero() {
@@ -99,18 +175,30 @@ func (r *TaggableResourceReconciler[T, P, PT]) Reconcile(ctx context.Context, re
 				return ctrl.Result{}, err
 			}
 			log.Info("resource deletion request received trying to delete associated tagValue/tagKey if unused")
-			projectID := r.determineProjectID(ctx, resource)
-			labels := resource.GetLabels()
-			for k, v := range r.LabelMatcher(labels) {
-				// return tagValue.Name, tagKey.Name, nil
-				valueID, keyID, err := r.getValueAndKeyID(ctx, projectID, k, v)
+			projectID, err := r.determineProjectID(ctx, resource)
+			if err != nil {
+				return ctrl.Result{Requeue: true, RequeueAfter: 10 * time.Second}, err
+			}
+			evaluation, err := r.TagEvaluator.Evaluate(ctx, resource)
+			if err != nil {
+				return ctrl.Result{Requeue: true, RequeueAfter: 10 * time.Second}, err
+			}
+			for _, tag := range evaluation.Tags {
+				valueID, keyID, err := r.getValueAndKeyID(ctx, projectID, tag.Key, tag.Value)
+				if gcp.IsNotFound(err) {
+					log.Info("tag value does not exist, nothing to delete", "key", tag.Key, "value", tag.Value)
+					continue
+				}
 				if err != nil {
+					r.recordGCPError(resource, err)
 					return ctrl.Result{Requeue: true, RequeueAfter: 10 * time.Second}, err
 				}
-				if err := r.TagsManager.DeleteValueIfUnused(ctx, projectID, keyID, valueID); err != nil {
+				if _, err := r.TagsManager.DeleteValueIfUnused(ctx, projectID, keyID, valueID); err != nil {
+					r.recordGCPError(resource, err)
 					return ctrl.Result{Requeue: true, RequeueAfter: 10 * time.Second}, err
 				}
 				if err := r.TagsManager.DeleteKeyIfUnused(ctx, projectID, keyID); err != nil {
+					r.recordGCPError(resource, err)
 					return ctrl.Result{Requeue: true, RequeueAfter: 10 * time.Second}, err
 				}
 			}
@@ -125,126 +213,472 @@ func (r *TaggableResourceReconciler[T, P, PT]) Reconcile(ctx context.Context, re
 		if err := r.Update(ctx, resource); err != nil {
 			return ctrl.Result{}, err
 		}
+		if err := r.setGroupVersionKind(resource); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	status := &taggingv1alpha1.TagAssignmentStatus{ObservedGeneration: resource.GetGeneration()}
+	result, err := r.reconcileTags(ctx, resource, status)
+	if statusErr := r.updateTagAssignment(ctx, resource, status, err); statusErr != nil {
+		log.Error(statusErr, "unable to update tag assignment")
+		if err == nil || result.RequeueAfter > 0 {
+			return ctrl.Result{}, statusErr
+		}
+	}
+	if err != nil && result.RequeueAfter > 0 {
+		// the resource is degraded, which is recorded in its TagAssignment, but pending operations and drift
+		// checks still need it to be requeued
+		return result, nil
 	}
+	return result, err
+}
+
+// reconcileTags creates, replaces and removes the tag bindings of a resource, recording the outcome in status.
+// Degraded resources which still need to be requeued are reported with a terminal error alongside a non-zero result.
+func (r *TaggableResourceReconciler[T, P, PT]) reconcileTags(ctx context.Context, resource PT, status *taggingv1alpha1.TagAssignmentStatus) (ctrl.Result, error) {
+	log := log.FromContext(ctx)
 
-	projectID := r.determineProjectID(ctx, resource)
+	projectID, err := r.determineProjectID(ctx, resource)
+	if err != nil {
+		log.Error(err, "unable to determine project")
+		return ctrl.Result{}, err
+	}
+	status.ProjectID = projectID
 	gvk := resource.GetObjectKind().GroupVersionKind()
 	ownerIndex := ownerIndexValue(gvk.GroupVersion().String(), gvk.Kind, resource.GetName())
 
-	var boundTags tagsv1alpha1.TagsLocationTagBindingList
-	if err := r.List(ctx, &boundTags, client.InNamespace(req.Namespace), client.MatchingFields{tagBindingOwnerKey: ownerIndex}); err != nil {
+	boundTags, err := listTagBindings(ctx, r, client.InNamespace(resource.GetNamespace()), client.MatchingFields{tagBindingOwnerKey: ownerIndex})
+	if err != nil {
 		log.Error(err, "unable to list bound tags")
 		return ctrl.Result{}, err
 	}
 
-	boundTagsMap := make(map[string]*tagsv1alpha1.TagsLocationTagBinding)
-	for _, tag := range boundTags.Items {
-		boundTagsMap[tag.Name] = &tag
+	boundTagsMap := make(map[string]client.Object)
+	for _, tag := range boundTags {
+		boundTagsMap[tag.GetName()] = tag
 	}
 
-	var expectedTagValueRefs []string
-	labels := resource.GetLabels()
+	evaluation, err := r.TagEvaluator.Evaluate(ctx, resource)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
 
-	for k, v := range r.LabelMatcher(labels) {
-		value, err := r.TagsManager.LookupValue(ctx, projectID, k, v)
+	var expectedTagValues []*resourcemanagerpb.TagValue
+	// index of the status of each expected tag value, tags waiting for an operation have none
+	var expectedTagIndexes []int
+	ctx = gcp.ContextWithCreationNotifier(ctx, &creationEventRecorder{recorder: r.Recorder, object: resource})
+	ctx = gcp.ContextWithNamespace(ctx, resource.GetNamespace())
+	network, err := r.resourceNetwork(resource, projectID)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	ctx = gcp.ContextWithNetwork(ctx, network)
+	if r.TagUserFromContext {
+		serviceAccount, err := r.configConnectorServiceAccount(ctx, resource.GetNamespace())
 		if err != nil {
+			log.Error(err, "unable to read ConfigConnectorContext")
 			return ctrl.Result{}, err
 		}
-		expectedTagValueRefs = append(expectedTagValueRefs, value.Name)
+		if serviceAccount != "" {
+			ctx = gcp.ContextWithTagUser(ctx, "serviceAccount:"+serviceAccount)
+		}
+	}
+	invalidTags := evaluation.InvalidTags
+
+	runningOperations, err := r.runningOperations(ctx, resource)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	for _, tag := range evaluation.Tags {
+		tagStatus := taggingv1alpha1.TagStatus{Key: tag.Label, Value: tag.Value, Policy: tag.Policy}
+		if tag.Key != tag.Label {
+			tagStatus.TagKey = tag.Key
+		}
+		status.Tags = append(status.Tags, tagStatus)
+		if op := pendingOperationFor(runningOperations, tag); op != nil {
+			status.PendingOperations = append(status.PendingOperations, *op)
+			continue
+		}
+		reason, err := r.checkFirewallTag(ctx, resource, projectID, tag.Key, &network)
+		if err != nil {
+			r.recordGCPError(resource, err)
+			return ctrl.Result{}, err
+		}
+		if reason != "" {
+			invalidTags = append(invalidTags, policy.InvalidTag{Label: tag.Label, Reason: reason})
+			continue
+		}
+		value, err := r.TagsManager.EnsureValue(ctx, projectID, tag.Key, tag.Value)
+		if pending, ok := gcp.AsOperationPending(err); ok {
+			log.Info("waiting for tag creation", "tag", pending.Name, "operation", pending.Operation)
+			status.PendingOperations = append(status.PendingOperations, newPendingOperation(pending, tag))
+			continue
+		}
+		if err != nil {
+			r.recordGCPError(resource, err)
+			return ctrl.Result{}, err
+		}
+		status.Tags[len(status.Tags)-1].TagValue = value.Name
+		status.Tags[len(status.Tags)-1].NamespacedName = value.NamespacedName
+		expectedTagValues = append(expectedTagValues, value)
+		expectedTagIndexes = append(expectedTagIndexes, len(status.Tags)-1)
 	}
 
 	projectInfo, err := r.TagsManager.GetProjectInfo(ctx, projectID)
 	if err != nil {
+		r.recordGCPError(resource, err)
 		return ctrl.Result{}, err
 	}
 
+	if provider, ok := any(r.MetadataProvider).(ResourceStatusProvider[T]); ok && !provider.HasResourceStatus(resource) {
+		log.Info("waiting for Config Connector to populate the resource status")
+		return ctrl.Result{RequeueAfter: resourceStatusPollInterval}, nil
+	}
+
+	resourceID, resolved, err := r.resourceID(ctx, projectInfo, resource)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if !resolved {
+		log.Info("waiting for referenced resources to be created")
+		return ctrl.Result{RequeueAfter: resourceStatusPollInterval}, nil
+	}
+
 	expectedResourceNames := make(map[string]bool)
-	for _, ref := range expectedTagValueRefs {
-		binding, err := r.generateBinding(resource, projectInfo, ref)
+	for i, value := range expectedTagValues {
+		binding, err := r.generateBinding(resource, projectInfo, resourceID, value.Name)
 		if err != nil {
 			return ctrl.Result{}, err
 		}
-		expectedResourceNames[binding.Name] = true
+		expectedResourceNames[binding.GetName()] = true
+		tagStatus := &status.Tags[expectedTagIndexes[i]]
+		tagStatus.BindingName = binding.GetName()
 
-		if existingBinding, exists := boundTagsMap[binding.Name]; exists && existingBinding.ObjectMeta.DeletionTimestamp.IsZero() {
+		if existingBinding, exists := boundTagsMap[binding.GetName()]; exists && existingBinding.GetDeletionTimestamp().IsZero() {
 			if tagBindingChanged(binding, existingBinding) {
 				// bindings are immutable, so we just always re-create
 				if err := r.Delete(ctx, existingBinding); err != nil {
+					r.recordTagBindingFailure(resource, binding.GetName(), err)
 					return ctrl.Result{}, err
 				}
 				if err := r.Create(ctx, binding); err != nil {
+					r.recordTagBindingFailure(resource, binding.GetName(), err)
 					return ctrl.Result{}, err
 				}
+				r.Recorder.Eventf(resource, corev1.EventTypeNormal, EventReasonTagBindingReplaced,
+					"Replaced tag binding %s for tag value %s", binding.GetName(), value.NamespacedName)
+			} else {
+				tagStatus.Ready = tagBindingReady(existingBinding)
 			}
 		} else {
 			if err := r.Create(ctx, binding); err != nil {
+				r.recordTagBindingFailure(resource, binding.GetName(), err)
 				return ctrl.Result{}, err
 			}
+			r.Recorder.Eventf(resource, corev1.EventTypeNormal, EventReasonTagBindingCreated,
+				"Created tag binding %s for tag value %s", binding.GetName(), value.NamespacedName)
 		}
 	}
 
-	for _, item := range boundTags.Items {
-		if _, exists := expectedResourceNames[item.Name]; !exists {
-			if err := r.Delete(ctx, &item); err != nil {
-				return ctrl.Result{}, client.IgnoreNotFound(err)
+	for _, item := range boundTags {
+		if _, exists := expectedResourceNames[item.GetName()]; !exists {
+			if err := r.Delete(ctx, item); err != nil {
+				if errors.IsNotFound(err) {
+					continue
+				}
+				r.recordTagBindingFailure(resource, item.GetName(), err)
+				return ctrl.Result{}, err
 			}
+			r.Recorder.Eventf(resource, corev1.EventTypeNormal, EventReasonTagBindingRemoved,
+				"Removed tag binding %s", item.GetName())
+		}
+	}
+
+	var degraded []string
+	if len(invalidTags) > 0 {
+		// retrying does not help, the resource is reconciled again once its labels change
+		var invalid []string
+		for _, tag := range invalidTags {
+			invalid = append(invalid, fmt.Sprintf("%s (%s)", tag.Label, tag.Reason))
+		}
+		message := strings.Join(invalid, ", ")
+		r.Recorder.Eventf(resource, corev1.EventTypeWarning, EventReasonInvalidTag,
+			"Labels cannot be mapped to tags: %s", message)
+		degraded = append(degraded, fmt.Sprintf("labels cannot be mapped to tags: %s", message))
+	}
+
+	if len(evaluation.MissingLabels) > 0 {
+		// the resource is tagged as far as possible and stays degraded until the labels are added,
+		// which triggers a new reconciliation
+		missing := strings.Join(evaluation.MissingLabels, ", ")
+		r.Recorder.Eventf(resource, corev1.EventTypeWarning, EventReasonRequiredLabelMissing,
+			"Missing labels required by tagging policies: %s", missing)
+		degraded = append(degraded, fmt.Sprintf("missing labels required by tagging policies: %s", missing))
+	}
+
+	var result ctrl.Result
+	if r.DriftCheckInterval > 0 {
+		if err := r.detectDrift(ctx, resource, resourceID, expectedTagValues, boundTagsMap); err != nil {
+			log.Error(err, "unable to detect tag binding drift")
 		}
+		result.RequeueAfter = r.DriftCheckInterval
+	}
+	if len(status.PendingOperations) > 0 && (result.RequeueAfter == 0 || result.RequeueAfter > pendingOperationPollInterval) {
+		result.RequeueAfter = pendingOperationPollInterval
+	}
+	if len(degraded) > 0 {
+		return result, reconcile.TerminalError(fmt.Errorf("%s", strings.Join(degraded, "; ")))
+	}
+	return result, nil
+}
+
+// resourceNetwork returns the VPC network of the resource from the FirewallNetworkLabel, if set.
+func (r *TaggableResourceReconciler[T, P, PT]) resourceNetwork(resource PT, projectID string) (string, error) {
+	if r.FirewallNetworkLabel == "" {
+		return "", nil
+	}
+	label := resource.GetLabels()[r.FirewallNetworkLabel]
+	if label == "" {
+		return "", nil
 	}
+	return gcp.NetworkID(projectID, label)
+}
 
-	return ctrl.Result{}, nil
+// checkFirewallTag returns why values of the tag key cannot be bound to the resource, if they are secure tags.
+// Secure tags are only bound to resources supporting them, and all secure tags of a resource must belong to its network.
+// network is the network of the resource, if unknown it is set to the network of the first secure tag.
+func (r *TaggableResourceReconciler[T, P, PT]) checkFirewallTag(ctx context.Context, resource PT, projectID string, key string, network *string) (string, error) {
+	keyNetwork, firewall, err := r.TagsManager.FirewallNetwork(ctx, projectID, key)
+	if err != nil || !firewall {
+		return "", err
+	}
+	if provider, ok := any(r.MetadataProvider).(FirewallTagsProvider); !ok || !provider.SupportsFirewallTags() {
+		return fmt.Sprintf("secure tags cannot be bound to %s", resource.GetObjectKind().GroupVersionKind().Kind), nil
+	}
+	switch {
+	case keyNetwork == "":
+		return "network of the secure tag key is unknown", nil
+	case *network == "":
+		*network = keyNetwork
+	case keyNetwork != *network:
+		return fmt.Sprintf("secure tag of network %s cannot be bound to a resource in network %s", keyNetwork, *network), nil
+	}
+	return "", nil
 }
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *TaggableResourceReconciler[T, P, PT]) SetupWithManager(mgr ctrl.Manager) error {
-	return ctrl.NewControllerManagedBy(mgr).
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), r.newPT(), projectRefKey, indexProjectRef); err != nil {
+		return err
+	}
+
+	b := ctrl.NewControllerManagedBy(mgr).
 		For(r.newPT()).
 		Owns(&tagsv1alpha1.TagsLocationTagBinding{}).
-		Complete(r)
+		Owns(&tagsv1beta1.TagsTagBinding{}).
+		Watches(&resourcemanagerv1beta1.Project{}, handler.EnqueueRequestsFromMapFunc(r.resourcesReferencingProject)).
+		// the status of policies changes with every tagged resource, only spec changes affect the tags
+		Watches(&taggingv1alpha1.TaggingPolicy{}, handler.EnqueueRequestsFromMapFunc(r.allResources),
+			builder.WithPredicates(predicate.GenerationChangedPredicate{}))
+	if r.TagEvaluator.UsesNamespaces() {
+		b = b.Watches(&corev1.Namespace{}, handler.EnqueueRequestsFromMapFunc(r.resourcesInNamespace),
+			builder.WithPredicates(predicate.Or(predicate.LabelChangedPredicate{}, predicate.AnnotationChangedPredicate{})))
+	}
+	return b.Complete(r)
 }
 
 func (r *TaggableResourceReconciler[T, P, PT]) newPT() PT {
 	return (PT)(new(T))
 }
 
-func (r *TaggableResourceReconciler[T, P, PT]) determineProjectID(ctx context.Context, resource PT) string {
+// setGroupVersionKind makes sure the TypeMeta is populated, which not every client does for typed objects.
+// It is needed to derive binding names and owner references.
+func (r *TaggableResourceReconciler[T, P, PT]) setGroupVersionKind(resource PT) error {
+	if !resource.GetObjectKind().GroupVersionKind().Empty() {
+		return nil
+	}
+	gvk, err := apiutil.GVKForObject(resource, r.Scheme)
+	if err != nil {
+		return err
+	}
+	resource.GetObjectKind().SetGroupVersionKind(gvk)
+	return nil
+}
+
+// newList creates an empty typed list for the reconciled resource kind.
+func (r *TaggableResourceReconciler[T, P, PT]) newList() (client.ObjectList, error) {
+	gvk, err := apiutil.GVKForObject(r.newPT(), r.Scheme)
+	if err != nil {
+		return nil, err
+	}
+	obj, err := r.Scheme.New(gvk.GroupVersion().WithKind(gvk.Kind + "List"))
+	if err != nil {
+		return nil, err
+	}
+	list, ok := obj.(client.ObjectList)
+	if !ok {
+		return nil, fmt.Errorf("%s is not a list type", gvk.Kind+"List")
+	}
+	return list, nil
+}
+
+// resourcesReferencingProject maps a Config Connector Project to all resources referencing it via spec.projectRef.
+func (r *TaggableResourceReconciler[T, P, PT]) resourcesReferencingProject(ctx context.Context, project client.Object) []reconcile.Request {
+	return r.requestsForResources(ctx, client.MatchingFields{projectRefKey: client.ObjectKeyFromObject(project).String()})
+}
+
+// allResources maps a TaggingPolicy to all resources of the reconciled kind, as any of them may be affected.
+func (r *TaggableResourceReconciler[T, P, PT]) allResources(ctx context.Context, _ client.Object) []reconcile.Request {
+	return r.requestsForResources(ctx)
+}
+
+// resourcesInNamespace maps a Namespace to all resources of the reconciled kind within it.
+func (r *TaggableResourceReconciler[T, P, PT]) resourcesInNamespace(ctx context.Context, namespace client.Object) []reconcile.Request {
+	return r.requestsForResources(ctx, client.InNamespace(namespace.GetName()))
+}
+
+func (r *TaggableResourceReconciler[T, P, PT]) requestsForResources(ctx context.Context, opts ...client.ListOption) []reconcile.Request {
 	log := log.FromContext(ctx)
 
-	// TODO projectRef
+	list, err := r.newList()
+	if err != nil {
+		log.Error(err, "unable to create resource list")
+		return nil
+	}
+	if err := r.List(ctx, list, opts...); err != nil {
+		log.Error(err, "unable to list resources")
+		return nil
+	}
+
+	items, err := meta.ExtractList(list)
+	if err != nil {
+		log.Error(err, "unable to extract resources")
+		return nil
+	}
+
+	requests := make([]reconcile.Request, 0, len(items))
+	for _, item := range items {
+		if obj, ok := item.(client.Object); ok {
+			requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(obj)})
+		}
+	}
+	return requests
+}
+
+func (r *TaggableResourceReconciler[T, P, PT]) determineProjectID(ctx context.Context, resource PT) (string, error) {
+	projectID, fallback, err := resolveProjectID(ctx, r, resource)
+	if err != nil {
+		return "", err
+	}
+	key := client.ObjectKeyFromObject(resource).String()
+	if !fallback {
+		r.fallbackProjects.Delete(key)
+		return projectID, nil
+	}
+	// only warn once instead of on every reconciliation, until the project of the resource changes
+	if previous, warned := r.fallbackProjects.Swap(key, projectID); !warned || previous != projectID {
+		r.Recorder.Eventf(resource, corev1.EventTypeWarning, EventReasonProjectResolutionFallback,
+			"No project reference or %s annotation found, using namespace name %s as project ID", projectIDAnnotation, resource.GetNamespace())
+	}
+	return projectID, nil
+}
+
+// resolveProjectID returns the project of a Config Connector resource, read from its spec.projectRef, its project ID
+// annotation or the project ID annotation of its namespace. Config Connector falls back to the namespace name, which
+// is returned with fallback set.
+func resolveProjectID(ctx context.Context, reader client.Reader, resource client.Object) (projectID string, fallback bool, err error) {
+	ref, err := projectReference(resource)
+	if err != nil {
+		return "", false, fmt.Errorf("failed to read project reference: %w", err)
+	}
+	if ref != nil {
+		if ref.External != "" {
+			return strings.TrimPrefix(ref.External, "projects/"), false, nil
+		}
+		if ref.Name != "" {
+			key := projectRefObjectKey(resource.GetNamespace(), ref)
+			var project resourcemanagerv1beta1.Project
+			if err := reader.Get(ctx, key, &project); err != nil {
+				return "", false, fmt.Errorf("failed to fetch referenced project %s: %w", key, err)
+			}
+			return ptr.Deref(project.Spec.ResourceID, project.Name), false, nil
+		}
+	}
 
 	if projectID, exists := resource.GetAnnotations()[projectIDAnnotation]; exists {
-		return projectID
+		return projectID, false, nil
 	}
 
 	var ns corev1.Namespace
-	if err := r.Get(ctx, types.NamespacedName{Name: resource.GetNamespace()}, &ns); err != nil {
-		log.Error(err, "unable to fetch namespace")
+	if err := reader.Get(ctx, types.NamespacedName{Name: resource.GetNamespace()}, &ns); err != nil {
+		log.FromContext(ctx).Error(err, "unable to fetch namespace")
 	}
 	if projectID, exists := ns.ObjectMeta.Annotations[projectIDAnnotation]; exists {
-		return projectID
+		return projectID, false, nil
 	}
+	return resource.GetNamespace(), true, nil
+}
 
-	return ns.Name
+// projectReference returns spec.projectRef of a Config Connector resource, or nil if it has none.
+func projectReference(resource client.Object) (*ccv1alpha1.ResourceRef, error) {
+	obj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(resource)
+	if err != nil {
+		return nil, err
+	}
+	raw, found, err := unstructured.NestedMap(obj, "spec", "projectRef")
+	if err != nil || !found {
+		return nil, err
+	}
+
+	var ref ccv1alpha1.ResourceRef
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(raw, &ref); err != nil {
+		return nil, err
+	}
+	return &ref, nil
 }
 
-func (r *TaggableResourceReconciler[T, P, PT]) generateBinding(resource PT, projectInfo *resourcemanagerpb.Project, tagValueID string) (*tagsv1alpha1.TagsLocationTagBinding, error) {
-	binding := &tagsv1alpha1.TagsLocationTagBinding{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:        tagBindingResourceName(resource, tagValueID),
-			Namespace:   resource.GetNamespace(),
-			Annotations: map[string]string{},
-		},
-		Spec: tagsv1alpha1.TagsLocationTagBindingSpec{
-			Location: r.MetadataProvider.GetResourceLocation(resource),
-			ParentRef: ccv1alpha1.ResourceRef{
-				External: r.MetadataProvider.GetResourceID(projectInfo, resource),
-			},
-			TagValueRef: ccv1alpha1.ResourceRef{
-				External: tagValueID,
-			},
-		},
+func projectRefObjectKey(namespace string, ref *ccv1alpha1.ResourceRef) types.NamespacedName {
+	if ref.Namespace != "" {
+		namespace = ref.Namespace
 	}
+	return types.NamespacedName{Namespace: namespace, Name: ref.Name}
+}
 
-	binding.Annotations[projectIDAnnotation] = projectInfo.ProjectId
+// configConnectorServiceAccount returns the Google service account Config Connector uses in the namespace,
+// or an empty string if the namespace has no ConfigConnectorContext.
+func (r *TaggableResourceReconciler[T, P, PT]) configConnectorServiceAccount(ctx context.Context, namespace string) (string, error) {
+	// read as unstructured, the Config Connector operator types are not part of the generated clients
+	cc := &unstructured.Unstructured{}
+	cc.SetGroupVersionKind(configConnectorContextGVK)
+	if err := r.Get(ctx, types.NamespacedName{Namespace: namespace, Name: configConnectorContextName}, cc); err != nil {
+		if errors.IsNotFound(err) {
+			return "", nil
+		}
+		return "", err
+	}
+	serviceAccount, _, err := unstructured.NestedString(cc.Object, "spec", "googleServiceAccount")
+	return serviceAccount, err
+}
+
+// resourceID returns the full resource name tags are bound to, or false while resources it refers to do not exist.
+func (r *TaggableResourceReconciler[T, P, PT]) resourceID(ctx context.Context, projectInfo *resourcemanagerpb.Project, resource PT) (string, bool, error) {
+	if provider, ok := any(r.MetadataProvider).(ResourceReferenceProvider[T]); ok {
+		return provider.ResolveResourceID(ctx, NewReferenceResolver(r), projectInfo, resource)
+	}
+	return r.MetadataProvider.GetResourceID(projectInfo, resource), true, nil
+}
+
+func (r *TaggableResourceReconciler[T, P, PT]) generateBinding(resource PT, projectInfo *resourcemanagerpb.Project, resourceID, tagValueID string) (client.Object, error) {
+	binding := newTagBinding(
+		r.MetadataProvider.GetResourceLocation(resource),
+		ccv1alpha1.ResourceRef{External: resourceID},
+		ccv1alpha1.ResourceRef{External: tagValueID},
+	)
+	binding.SetName(tagBindingResourceName(resource, tagValueID))
+	binding.SetNamespace(resource.GetNamespace())
+	binding.SetAnnotations(map[string]string{projectIDAnnotation: projectInfo.ProjectId})
 
 	if err := ctrl.SetControllerReference(resource, binding, r.Scheme); err != nil {
 		return nil, err
@@ -267,21 +701,23 @@ func tagBindingResourceName(owner client.Object, valueRef string) string {
 	return prefix + suffix
 }
 
-func tagBindingChanged(expected, actual *tagsv1alpha1.TagsLocationTagBinding) bool {
-	if !equality.Semantic.DeepEqual(actual.Spec.TagValueRef, expected.Spec.TagValueRef) {
+func tagBindingChanged(expected, actual client.Object) bool {
+	expectedLocation, expectedParentRef, expectedTagValueRef := tagBindingSpec(expected)
+	actualLocation, actualParentRef, actualTagValueRef := tagBindingSpec(actual)
+	if !equality.Semantic.DeepEqual(actualTagValueRef, expectedTagValueRef) {
 		return true
 	}
 
-	if !equality.Semantic.DeepEqual(actual.Spec.ParentRef, expected.Spec.ParentRef) {
+	if !equality.Semantic.DeepEqual(actualParentRef, expectedParentRef) {
 		return true
 	}
 
-	if expected.Spec.Location != actual.Spec.Location {
+	if expectedLocation != actualLocation {
 		return true
 	}
 
-	expectedProjectID, _ := expected.ObjectMeta.Annotations[projectIDAnnotation]
-	actualProjectID, _ := actual.ObjectMeta.Annotations[projectIDAnnotation]
+	expectedProjectID, _ := expected.GetAnnotations()[projectIDAnnotation]
+	actualProjectID, _ := actual.GetAnnotations()[projectIDAnnotation]
 	if expectedProjectID != actualProjectID {
 		return true
 	}
@@ -290,26 +726,35 @@ func tagBindingChanged(expected, actual *tagsv1alpha1.TagsLocationTagBinding) bo
 }
 
 func SetupTagBindingIndex(mgr ctrl.Manager) error {
-	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &tagsv1alpha1.TagsLocationTagBinding{}, tagBindingOwnerKey, func(rawObj client.Object) []string {
-		// grab the tags location binding object, extract the owner...
-		job := rawObj.(*tagsv1alpha1.TagsLocationTagBinding)
-		owner := metav1.GetControllerOf(job)
-		if owner == nil {
-			return nil
-		}
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &tagsv1alpha1.TagsLocationTagBinding{}, tagBindingOwnerKey, indexTagBindingOwner); err != nil {
+		return err
+	}
+	return mgr.GetFieldIndexer().IndexField(context.Background(), &tagsv1beta1.TagsTagBinding{}, tagBindingOwnerKey, indexTagBindingOwner)
+}
 
-		// ...make sure it's a config connector resource...
-		if !strings.Contains(owner.APIVersion, ".cnrm.cloud.google.com") {
-			return nil
-		}
+// indexProjectRef indexes resources by the Project they reference by name in spec.projectRef.
+func indexProjectRef(rawObj client.Object) []string {
+	ref, err := projectReference(rawObj)
+	if err != nil || ref == nil || ref.Name == "" {
+		return nil
+	}
+	return []string{projectRefObjectKey(rawObj.GetNamespace(), ref).String()}
+}
 
-		// ...and if so, return it
-		return []string{ownerIndexValue(owner.APIVersion, owner.Kind, owner.Name)}
-	}); err != nil {
-		return err
+func indexTagBindingOwner(rawObj client.Object) []string {
+	// grab the tag binding object, extract the owner...
+	owner := metav1.GetControllerOf(rawObj)
+	if owner == nil {
+		return nil
 	}
 
-	return nil
+	// ...make sure it's a config connector resource...
+	if !strings.Contains(owner.APIVersion, ".cnrm.cloud.google.com") {
+		return nil
+	}
+
+	// ...and if so, return it
+	return []string{ownerIndexValue(owner.APIVersion, owner.Kind, owner.Name)}
 }
 
 func ownerIndexValue(apiVersion string, kind string, name string) string {
@@ -326,16 +771,15 @@ func (r *TaggableResourceReconciler[T, P, PT]) handleTagBindingsDeletion(ctx con
 	gvk := resource.GetObjectKind().GroupVersionKind()
 	ownerIndex := ownerIndexValue(gvk.GroupVersion().String(), gvk.Kind, resource.GetName())
 
-	var boundTags tagsv1alpha1.TagsLocationTagBindingList
-	if err := r.List(ctx, &boundTags, client.InNamespace(resource.GetNamespace()), client.MatchingFields{tagBindingOwnerKey: ownerIndex}); err != nil {
+	boundTags, err := listTagBindings(ctx, r, client.InNamespace(resource.GetNamespace()), client.MatchingFields{tagBindingOwnerKey: ownerIndex})
+	if err != nil {
 		log.Error(err, "failed to list bound tags")
 		return fmt.Errorf("failed to list bound tags: %w", err)
 	}
 
-	var err error
-	for _, tagBinding := range boundTags.Items {
+	for _, tagBinding := range boundTags {
 		// Skip if tag binding is already being deleted
-		if !tagBinding.ObjectMeta.DeletionTimestamp.IsZero() {
+		if !tagBinding.GetDeletionTimestamp().IsZero() {
 			continue
 		}
 
@@ -343,38 +787,44 @@ func (r *TaggableResourceReconciler[T, P, PT]) handleTagBindingsDeletion(ctx con
 		finalizers := []string{"cnrm.cloud.google.com/finalizer", "cnrm.cloud.google.com/deletion-defender"}
 		modified := false
 		for _, f := range finalizers {
-			if controllerutil.ContainsFinalizer(&tagBinding, f) {
-				controllerutil.RemoveFinalizer(&tagBinding, f)
+			if controllerutil.ContainsFinalizer(tagBinding, f) {
+				controllerutil.RemoveFinalizer(tagBinding, f)
 				modified = true
 			}
 		}
 
 		if modified {
-			if updateErr := r.Update(ctx, &tagBinding); updateErr != nil && !errors.IsNotFound(updateErr) {
-				log.Error(updateErr, "failed to remove finalizers", "tagBinding", tagBinding.Name)
-				err = fmt.Errorf("failed to remove finalizers from %s: %w", tagBinding.Name, updateErr)
+			if updateErr := r.Update(ctx, tagBinding); updateErr != nil && !errors.IsNotFound(updateErr) {
+				log.Error(updateErr, "failed to remove finalizers", "tagBinding", tagBinding.GetName())
+				err = fmt.Errorf("failed to remove finalizers from %s: %w", tagBinding.GetName(), updateErr)
+				r.recordTagBindingFailure(resource, tagBinding.GetName(), updateErr)
 				continue
 			}
 		}
 
 		// Delete the tag binding
-		log.Info("deleting tag binding", "name", tagBinding.Name)
-		if deleteErr := r.Delete(ctx, &tagBinding); deleteErr != nil && !errors.IsNotFound(deleteErr) {
-			log.Error(deleteErr, "failed to delete tag binding", "tagBinding", tagBinding.Name)
-			err = fmt.Errorf("failed to delete tag binding %s: %w", tagBinding.Name, deleteErr)
+		log.Info("deleting tag binding", "name", tagBinding.GetName())
+		if deleteErr := r.Delete(ctx, tagBinding); deleteErr != nil && !errors.IsNotFound(deleteErr) {
+			log.Error(deleteErr, "failed to delete tag binding", "tagBinding", tagBinding.GetName())
+			err = fmt.Errorf("failed to delete tag binding %s: %w", tagBinding.GetName(), deleteErr)
+			r.recordTagBindingFailure(resource, tagBinding.GetName(), deleteErr)
+			continue
 		}
+		r.Recorder.Eventf(resource, corev1.EventTypeNormal, EventReasonTagBindingRemoved,
+			"Removed tag binding %s", tagBinding.GetName())
 	}
 
 	return err
 }
 
+// getValueAndKeyID looks up the IDs of an existing tag value and its key, without creating them.
 func (r *TaggableResourceReconciler[T, P, PT]) getValueAndKeyID(ctx context.Context, projectID, key, value string) (string, string, error) {
-	tagValue, err := r.TagsManager.LookupValue(ctx, projectID, key, value)
+	tagValue, err := r.TagsManager.GetValue(ctx, projectID, key, value)
 	if err != nil {
 		return "", "", fmt.Errorf("failed to lookup tag value: %w", err)
 	}
 
-	tagKey, err := r.TagsManager.LookupKey(ctx, projectID, key)
+	tagKey, err := r.TagsManager.GetKey(ctx, projectID, key)
 	if err != nil {
 		return "", "", fmt.Errorf("failed to lookup tag key: %w", err)
 	}
@@ -382,13 +832,22 @@ func (r *TaggableResourceReconciler[T, P, PT]) getValueAndKeyID(ctx context.Cont
 	return tagValue.Name, tagKey.Name, nil
 }
 
-func CreateTaggableResourceController[T any, P ResourceMetadataProvider[T], PT ResourcePointer[T]](mgr ctrl.Manager, tagsManager gcp.TagsManager, provider P, labelMatcher func(map[string]string) map[string]string) {
+func CreateTaggableResourceController[T any, P ResourceMetadataProvider[T], PT ResourcePointer[T]](mgr ctrl.Manager, tagsManager gcp.TagsManager, provider P, tagEvaluator TagEvaluator, opts ReconcilerOptions) {
+	k8sClient := mgr.GetClient()
+	recorder := mgr.GetEventRecorderFor("gcp-config-connector-tagging-operator")
+	if opts.DryRun != nil {
+		k8sClient = dryrun.NewClient(k8sClient, opts.DryRun)
+		recorder = dryrun.NewEventRecorder(recorder)
+	}
+
 	if err := (&TaggableResourceReconciler[T, P, PT]{
-		Client:           mgr.GetClient(),
-		Scheme:           mgr.GetScheme(),
-		TagsManager:      tagsManager,
-		MetadataProvider: provider,
-		LabelMatcher:     labelMatcher,
+		Client:            k8sClient,
+		Scheme:            mgr.GetScheme(),
+		TagsManager:       tagsManager,
+		MetadataProvider:  provider,
+		TagEvaluator:      tagEvaluator,
+		Recorder:          recorder,
+		ReconcilerOptions: opts,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create taggable resource controller")
 		os.Exit(1)
@@ -17,15 +17,33 @@ limitations under the License.
 package controller
 
 import (
+	"context"
+
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 
+	"cloud.google.com/go/resourcemanager/apiv3/resourcemanagerpb"
+	bigqueryv1beta1 "github.com/GoogleCloudPlatform/k8s-config-connector/pkg/clients/generated/apis/bigquery/v1beta1"
+	ccv1alpha1 "github.com/GoogleCloudPlatform/k8s-config-connector/pkg/clients/generated/apis/k8s/v1alpha1"
+	resourcemanagerv1beta1 "github.com/GoogleCloudPlatform/k8s-config-connector/pkg/clients/generated/apis/resourcemanager/v1beta1"
+	storagev1beta1 "github.com/GoogleCloudPlatform/k8s-config-connector/pkg/clients/generated/apis/storage/v1beta1"
 	tagsv1alpha1 "github.com/GoogleCloudPlatform/k8s-config-connector/pkg/clients/generated/apis/tags/v1alpha1"
 	"github.com/stretchr/testify/mock"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/deliveryhero/gcp-config-connector-tagging-operator/internal/policy"
+	"github.com/deliveryhero/gcp-config-connector-tagging-operator/internal/util"
 )
 
 var _ = Describe("Taggable Resource Controller", func() {
@@ -139,6 +157,259 @@ var _ = Describe("Taggable Resource Controller", func() {
 	})
 })
 
+var _ = Describe("Project reference resolution", func() {
+	Describe("ProjectReference function", func() {
+		tests := []struct {
+			name     string
+			resource client.Object
+			want     *ccv1alpha1.ResourceRef
+		}{
+			{
+				name:     "resource without projectRef field",
+				resource: &storagev1beta1.StorageBucket{ObjectMeta: metav1.ObjectMeta{Name: "test-bucket"}},
+				want:     nil,
+			},
+			{
+				name:     "resource with unset projectRef",
+				resource: &bigqueryv1beta1.BigQueryDataset{ObjectMeta: metav1.ObjectMeta{Name: "test-dataset"}},
+				want:     nil,
+			},
+			{
+				name: "resource with external projectRef",
+				resource: &bigqueryv1beta1.BigQueryDataset{
+					ObjectMeta: metav1.ObjectMeta{Name: "test-dataset"},
+					Spec: bigqueryv1beta1.BigQueryDatasetSpec{
+						ProjectRef: &ccv1alpha1.ResourceRef{External: "projects/test-project"},
+					},
+				},
+				want: &ccv1alpha1.ResourceRef{External: "projects/test-project"},
+			},
+			{
+				name: "resource with named projectRef",
+				resource: &bigqueryv1beta1.BigQueryDataset{
+					ObjectMeta: metav1.ObjectMeta{Name: "test-dataset"},
+					Spec: bigqueryv1beta1.BigQueryDatasetSpec{
+						ProjectRef: &ccv1alpha1.ResourceRef{Name: "test-project", Namespace: "projects"},
+					},
+				},
+				want: &ccv1alpha1.ResourceRef{Name: "test-project", Namespace: "projects"},
+			},
+		}
+
+		for _, tt := range tests {
+			tt := tt
+			It("should return the correct reference for "+tt.name, func() {
+				got, err := projectReference(tt.resource)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(got).To(Equal(tt.want))
+			})
+		}
+	})
+
+	Describe("ProjectRefObjectKey function", func() {
+		tests := []struct {
+			name      string
+			namespace string
+			ref       *ccv1alpha1.ResourceRef
+			want      types.NamespacedName
+		}{
+			{
+				name:      "reference in the same namespace",
+				namespace: "team-a",
+				ref:       &ccv1alpha1.ResourceRef{Name: "test-project"},
+				want:      types.NamespacedName{Namespace: "team-a", Name: "test-project"},
+			},
+			{
+				name:      "reference in another namespace",
+				namespace: "team-a",
+				ref:       &ccv1alpha1.ResourceRef{Name: "test-project", Namespace: "projects"},
+				want:      types.NamespacedName{Namespace: "projects", Name: "test-project"},
+			},
+		}
+
+		for _, tt := range tests {
+			tt := tt
+			It("should return the correct object key for "+tt.name, func() {
+				got := projectRefObjectKey(tt.namespace, tt.ref)
+				Expect(got).To(Equal(tt.want))
+			})
+		}
+	})
+})
+
+// testDatasetMetadataProvider provides the metadata of BigQuery datasets, which have a spec.projectRef.
+type testDatasetMetadataProvider struct{}
+
+func (in *testDatasetMetadataProvider) GetResourceLocation(_ *bigqueryv1beta1.BigQueryDataset) string {
+	return "eu"
+}
+
+func (in *testDatasetMetadataProvider) GetResourceID(projectInfo *resourcemanagerpb.Project, r *bigqueryv1beta1.BigQueryDataset) string {
+	return "//bigquery.googleapis.com/projects/" + projectInfo.ProjectId + "/datasets/" + r.Name
+}
+
+type testDatasetReconciler = TaggableResourceReconciler[bigqueryv1beta1.BigQueryDataset, *testDatasetMetadataProvider, *bigqueryv1beta1.BigQueryDataset]
+
+func newTestDatasetReconciler(objs ...client.Object) *testDatasetReconciler {
+	scheme := runtime.NewScheme()
+	Expect(corev1.AddToScheme(scheme)).To(Succeed())
+	Expect(bigqueryv1beta1.AddToScheme(scheme)).To(Succeed())
+	Expect(resourcemanagerv1beta1.AddToScheme(scheme)).To(Succeed())
+
+	return &testDatasetReconciler{
+		Client: fake.NewClientBuilder().
+			WithScheme(scheme).
+			WithObjects(objs...).
+			WithIndex(&bigqueryv1beta1.BigQueryDataset{}, projectRefKey, indexProjectRef).
+			Build(),
+		Scheme:           scheme,
+		MetadataProvider: &testDatasetMetadataProvider{},
+		Recorder:         record.NewFakeRecorder(10),
+	}
+}
+
+var _ = Describe("Project determination", func() {
+	dataset := func(namespace, name string, projectRef *ccv1alpha1.ResourceRef) *bigqueryv1beta1.BigQueryDataset {
+		return &bigqueryv1beta1.BigQueryDataset{
+			ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+			Spec:       bigqueryv1beta1.BigQueryDatasetSpec{ProjectRef: projectRef},
+		}
+	}
+	project := func(namespace, name string, resourceID *string) *resourcemanagerv1beta1.Project {
+		return &resourcemanagerv1beta1.Project{
+			ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+			Spec:       resourcemanagerv1beta1.ProjectSpec{ResourceID: resourceID},
+		}
+	}
+
+	tests := []struct {
+		name       string
+		projectRef *ccv1alpha1.ResourceRef
+		objs       []client.Object
+		want       string
+	}{
+		{
+			name:       "external reference with projects/ prefix",
+			projectRef: &ccv1alpha1.ResourceRef{External: "projects/external-project"},
+			want:       "external-project",
+		},
+		{
+			name:       "external reference without prefix",
+			projectRef: &ccv1alpha1.ResourceRef{External: "external-project"},
+			want:       "external-project",
+		},
+		{
+			name:       "reference by name to a Project with resourceID",
+			projectRef: &ccv1alpha1.ResourceRef{Name: "test-project", Namespace: "projects"},
+			objs:       []client.Object{project("projects", "test-project", ptr.To("resolved-project-id"))},
+			want:       "resolved-project-id",
+		},
+		{
+			name:       "reference by name to a Project without resourceID",
+			projectRef: &ccv1alpha1.ResourceRef{Name: "test-project"},
+			objs:       []client.Object{project("default", "test-project", nil)},
+			want:       "test-project",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		It("should resolve the project of a "+tt.name, func() {
+			reconciler := newTestDatasetReconciler(tt.objs...)
+			got, err := reconciler.determineProjectID(context.Background(), dataset("default", "test-dataset", tt.projectRef))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(got).To(Equal(tt.want))
+			Expect(reconciler.Recorder.(*record.FakeRecorder).Events).To(BeEmpty())
+		})
+	}
+
+	It("should fail if the referenced Project does not exist", func() {
+		reconciler := newTestDatasetReconciler()
+		_, err := reconciler.determineProjectID(context.Background(),
+			dataset("default", "test-dataset", &ccv1alpha1.ResourceRef{Name: "missing-project"}))
+		Expect(err).To(MatchError(ContainSubstring("failed to fetch referenced project default/missing-project")))
+		Expect(errors.IsNotFound(err)).To(BeTrue())
+	})
+
+	It("should enqueue the resources referencing a Project", func() {
+		reconciler := newTestDatasetReconciler(
+			dataset("projects", "same-namespace", &ccv1alpha1.ResourceRef{Name: "test-project"}),
+			dataset("team-a", "other-namespace", &ccv1alpha1.ResourceRef{Name: "test-project", Namespace: "projects"}),
+			dataset("team-a", "other-project", &ccv1alpha1.ResourceRef{Name: "other-project", Namespace: "projects"}),
+			dataset("team-a", "external", &ccv1alpha1.ResourceRef{External: "projects/test-project"}),
+			dataset("team-a", "annotated", nil),
+		)
+
+		requests := reconciler.resourcesReferencingProject(context.Background(), project("projects", "test-project", nil))
+		Expect(requests).To(ConsistOf(
+			reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "projects", Name: "same-namespace"}},
+			reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "team-a", Name: "other-namespace"}},
+		))
+	})
+})
+
+var _ = Describe("Resource deletion", func() {
+	It("should delete existing tags without creating missing ones", func() {
+		now := metav1.Now()
+		bucket := &storagev1beta1.StorageBucket{ObjectMeta: metav1.ObjectMeta{
+			Namespace:         "default",
+			Name:              "test-bucket",
+			Labels:            map[string]string{"team": "payments", "env": "prod"},
+			Annotations:       map[string]string{projectIDAnnotation: "test-project"},
+			Finalizers:        []string{taggableResourceFinalizer},
+			DeletionTimestamp: &now,
+		}}
+		tagsManager := &fakeTagsManager{missingValues: map[string]bool{"env/prod": true}}
+		reconciler := newTestBucketReconciler(tagsManager, bucket)
+
+		_, err := reconciler.Reconcile(context.Background(), ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "test-bucket"}})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(tagsManager.ensured).To(BeZero())
+		Expect(tagsManager.deletedValues).To(ConsistOf("tagValues/team-payments"))
+		Expect(tagsManager.deletedKeys).To(ConsistOf("tagKeys/team"))
+	})
+})
+
+var _ = Describe("Namespace default tags", func() {
+	It("should enqueue all resources of a changed namespace", func() {
+		bucket := func(namespace, name string) *storagev1beta1.StorageBucket {
+			return &storagev1beta1.StorageBucket{ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name}}
+		}
+		reconciler := newTestBucketReconciler(&fakeTagsManager{}, bucket("team-a", "one"), bucket("team-a", "two"), bucket("team-b", "three"))
+
+		requests := reconciler.resourcesInNamespace(context.Background(), &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "team-a"}})
+		Expect(requests).To(ConsistOf(
+			reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "team-a", Name: "one"}},
+			reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "team-a", Name: "two"}},
+		))
+	})
+
+	It("should tag resources with the labels of their namespace", func() {
+		namespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+			Name:        "default",
+			Labels:      map[string]string{"team": "search"},
+			Annotations: map[string]string{projectIDAnnotation: "test-project"},
+		}}
+		bucket := &storagev1beta1.StorageBucket{ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "test-bucket",
+			Labels:    map[string]string{"env": "prod"},
+		}}
+		reconciler := newTestBucketReconciler(&fakeTagsManager{}, namespace, bucket)
+		teamOnly, err := util.LimitLabelsWithRegex("^team$")
+		Expect(err).NotTo(HaveOccurred())
+		reconciler.TagEvaluator = policy.NewEvaluator(reconciler.Client, teamOnly, policy.WithNamespaceLabels(teamOnly))
+
+		_, err = reconciler.Reconcile(context.Background(), ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "test-bucket"}})
+		Expect(err).NotTo(HaveOccurred())
+
+		var bindings tagsv1alpha1.TagsLocationTagBindingList
+		Expect(reconciler.List(context.Background(), &bindings, client.InNamespace("default"))).To(Succeed())
+		Expect(bindings.Items).To(HaveLen(1))
+		Expect(bindings.Items[0].Name).To(Equal("storagebucket-test-bucket-team-search"))
+	})
+})
+
 type MockObject struct {
 	mock.Mock
 	metav1.ObjectMeta
@@ -0,0 +1,140 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	taggingv1alpha1 "github.com/deliveryhero/gcp-config-connector-tagging-operator/api/v1alpha1"
+	"github.com/deliveryhero/gcp-config-connector-tagging-operator/internal/policy"
+)
+
+const (
+	tagAssignmentPolicyKey = ".status.tags.policy"
+
+	reasonRulesValid   = "RulesValid"
+	reasonInvalidRules = "InvalidRules"
+)
+
+// +kubebuilder:rbac:groups=tagging.gdp.deliveryhero.io,resources=taggingpolicies,verbs=get;list;watch
+// +kubebuilder:rbac:groups=tagging.gdp.deliveryhero.io,resources=taggingpolicies/status,verbs=get;update;patch
+
+// TaggingPolicyReconciler validates TaggingPolicies and counts the resources they tag.
+type TaggingPolicyReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+}
+
+func (r *TaggingPolicyReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := log.FromContext(ctx)
+
+	var taggingPolicy taggingv1alpha1.TaggingPolicy
+	if err := r.Get(ctx, req.NamespacedName, &taggingPolicy); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	var assignments taggingv1alpha1.TagAssignmentList
+	if err := r.List(ctx, &assignments, client.MatchingFields{tagAssignmentPolicyKey: taggingPolicy.Name}); err != nil {
+		log.Error(err, "unable to list tag assignments")
+		return ctrl.Result{}, err
+	}
+
+	status := taggingPolicy.Status.DeepCopy()
+	status.MatchedResources = int32(len(assignments.Items))
+	status.ObservedGeneration = taggingPolicy.Generation
+
+	var invalid []string
+	for i, rule := range taggingPolicy.Spec.Rules {
+		if err := policy.ValidateRule(rule); err != nil {
+			invalid = append(invalid, fmt.Sprintf("rule %d: %v", i, err))
+		}
+	}
+	condition := metav1.Condition{
+		Type:               taggingv1alpha1.ConditionReady,
+		Status:             metav1.ConditionTrue,
+		Reason:             reasonRulesValid,
+		Message:            "All rules are valid",
+		ObservedGeneration: taggingPolicy.Generation,
+	}
+	if len(invalid) > 0 {
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = reasonInvalidRules
+		condition.Message = strings.Join(invalid, "; ")
+	}
+	meta.SetStatusCondition(&status.Conditions, condition)
+
+	if equality.Semantic.DeepEqual(taggingPolicy.Status, *status) {
+		return ctrl.Result{}, nil
+	}
+	taggingPolicy.Status = *status
+	if err := r.Status().Update(ctx, &taggingPolicy); err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to update tagging policy status: %w", err)
+	}
+	return ctrl.Result{}, nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *TaggingPolicyReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &taggingv1alpha1.TagAssignment{}, tagAssignmentPolicyKey, indexTagAssignmentPolicies); err != nil {
+		return err
+	}
+
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&taggingv1alpha1.TaggingPolicy{}).
+		Watches(&taggingv1alpha1.TagAssignment{}, handler.EnqueueRequestsFromMapFunc(policiesOfTagAssignment)).
+		Complete(r)
+}
+
+// indexTagAssignmentPolicies indexes TagAssignments by the policies which produced their tags.
+func indexTagAssignmentPolicies(rawObj client.Object) []string {
+	assignment, ok := rawObj.(*taggingv1alpha1.TagAssignment)
+	if !ok {
+		return nil
+	}
+	var policies []string
+	seen := make(map[string]bool)
+	for _, tag := range assignment.Status.Tags {
+		if tag.Policy != "" && !seen[tag.Policy] {
+			seen[tag.Policy] = true
+			policies = append(policies, tag.Policy)
+		}
+	}
+	return policies
+}
+
+// policiesOfTagAssignment maps a TagAssignment to the policies which produced its tags. Updates are
+// mapped for both the old and new object, so policies no longer applying are recounted as well.
+func policiesOfTagAssignment(_ context.Context, obj client.Object) []reconcile.Request {
+	var requests []reconcile.Request
+	for _, name := range indexTagAssignmentPolicies(obj) {
+		requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Name: name}})
+	}
+	return requests
+}
@@ -0,0 +1,127 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	storagev1beta1 "github.com/GoogleCloudPlatform/k8s-config-connector/pkg/clients/generated/apis/storage/v1beta1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	taggingv1alpha1 "github.com/deliveryhero/gcp-config-connector-tagging-operator/api/v1alpha1"
+)
+
+var _ = Describe("TaggingPolicy Controller", func() {
+	var ctx context.Context
+
+	BeforeEach(func() {
+		ctx = context.Background()
+	})
+
+	It("should count matched resources and validate rules", func() {
+		taggingPolicy := &taggingv1alpha1.TaggingPolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: "teams", Generation: 2},
+			Spec: taggingv1alpha1.TaggingPolicySpec{Rules: []taggingv1alpha1.TagRule{
+				{Label: "team"},
+				{LabelRegex: "("},
+			}},
+		}
+		assignment := func(name, policyName string) *taggingv1alpha1.TagAssignment {
+			return &taggingv1alpha1.TagAssignment{
+				ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+				Status: taggingv1alpha1.TagAssignmentStatus{Tags: []taggingv1alpha1.TagStatus{
+					{Key: "team", Value: "payments", Policy: policyName},
+					{Key: "env", Value: "prod", Policy: policyName},
+				}},
+			}
+		}
+
+		scheme := runtime.NewScheme()
+		Expect(taggingv1alpha1.AddToScheme(scheme)).To(Succeed())
+		reconciler := &TaggingPolicyReconciler{
+			Client: fake.NewClientBuilder().
+				WithScheme(scheme).
+				WithObjects(taggingPolicy, assignment("a", "teams"), assignment("b", "teams"), assignment("c", "other")).
+				WithStatusSubresource(taggingPolicy).
+				WithIndex(&taggingv1alpha1.TagAssignment{}, tagAssignmentPolicyKey, indexTagAssignmentPolicies).
+				Build(),
+			Scheme: scheme,
+		}
+
+		_, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: "teams"}})
+		Expect(err).NotTo(HaveOccurred())
+
+		var updated taggingv1alpha1.TaggingPolicy
+		Expect(reconciler.Get(ctx, types.NamespacedName{Name: "teams"}, &updated)).To(Succeed())
+		Expect(updated.Status.MatchedResources).To(Equal(int32(2)))
+		Expect(updated.Status.ObservedGeneration).To(Equal(int64(2)))
+		condition := meta.FindStatusCondition(updated.Status.Conditions, taggingv1alpha1.ConditionReady)
+		Expect(condition).NotTo(BeNil())
+		Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+		Expect(condition.Message).To(HavePrefix("rule 1: invalid labelRegex"))
+	})
+
+	It("should tag resources according to the policies", func() {
+		taggingPolicy := &taggingv1alpha1.TaggingPolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: "teams"},
+			Spec: taggingv1alpha1.TaggingPolicySpec{Rules: []taggingv1alpha1.TagRule{
+				{Label: "team", TagKey: "owner", ValueTransform: taggingv1alpha1.ValueTransformLowercase},
+				{Label: "cost-center", Required: true},
+			}},
+		}
+		bucket := &storagev1beta1.StorageBucket{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "test-bucket",
+				Namespace:   "default",
+				Labels:      map[string]string{"team": "Payments", "env": "prod"},
+				Annotations: map[string]string{projectIDAnnotation: "test-project"},
+			},
+		}
+		reconciler := newTestBucketReconciler(&fakeTagsManager{}, bucket, taggingPolicy)
+
+		_, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "test-bucket"}})
+		Expect(err).To(MatchError(ContainSubstring("missing labels required by tagging policies: cost-center")))
+		Expect(errors.Is(err, reconcile.TerminalError(nil))).To(BeTrue())
+		events := reconciler.Recorder.(*record.FakeRecorder).Events
+		Expect(events).To(Receive(HavePrefix("Normal TagBindingCreated")))
+		Expect(events).To(Receive(HavePrefix("Warning RequiredLabelMissing")))
+
+		var assignment taggingv1alpha1.TagAssignment
+		Expect(reconciler.Get(ctx, types.NamespacedName{Namespace: "default", Name: "storagebucket-test-bucket"}, &assignment)).To(Succeed())
+		Expect(assignment.Status.Tags).To(Equal([]taggingv1alpha1.TagStatus{{
+			Key:            "team",
+			Value:          "payments",
+			TagKey:         "owner",
+			Policy:         "teams",
+			TagValue:       "tagValues/owner-payments",
+			NamespacedName: "test-project/owner/payments",
+			BindingName:    "storagebucket-test-bucket-owner-payments",
+		}}))
+		Expect(meta.IsStatusConditionTrue(assignment.Status.Conditions, taggingv1alpha1.ConditionDegraded)).To(BeTrue())
+	})
+})
@@ -0,0 +1,100 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dryrun
+
+import (
+	"context"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// dryRunClient sends all writes as server side dry-run requests and records them.
+type dryRunClient struct {
+	client.Client
+	recorder *Recorder
+}
+
+// NewClient wraps c so it never persists changes, while writes are still validated by the API server.
+func NewClient(c client.Client, recorder *Recorder) client.Client {
+	return &dryRunClient{Client: client.NewDryRunClient(c), recorder: recorder}
+}
+
+func (c *dryRunClient) Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+	c.record(ctx, "create", obj)
+	return c.Client.Create(ctx, obj, opts...)
+}
+
+func (c *dryRunClient) Update(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error {
+	c.record(ctx, "update", obj)
+	return c.Client.Update(ctx, obj, opts...)
+}
+
+func (c *dryRunClient) Delete(ctx context.Context, obj client.Object, opts ...client.DeleteOption) error {
+	c.record(ctx, "delete", obj)
+	return c.Client.Delete(ctx, obj, opts...)
+}
+
+func (c *dryRunClient) DeleteAllOf(ctx context.Context, obj client.Object, opts ...client.DeleteAllOfOption) error {
+	c.record(ctx, "deletecollection", obj)
+	return c.Client.DeleteAllOf(ctx, obj, opts...)
+}
+
+func (c *dryRunClient) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+	c.record(ctx, "patch", obj)
+	return c.Client.Patch(ctx, obj, patch, opts...)
+}
+
+func (c *dryRunClient) Status() client.SubResourceWriter {
+	return c.SubResource("status")
+}
+
+func (c *dryRunClient) SubResource(subResource string) client.SubResourceClient {
+	return &dryRunSubResourceClient{
+		SubResourceClient: c.Client.SubResource(subResource),
+		client:            c,
+		subResource:       subResource,
+	}
+}
+
+func (c *dryRunClient) record(ctx context.Context, verb string, obj client.Object) {
+	kind := "unknown"
+	if gvk, err := c.GroupVersionKindFor(obj); err == nil {
+		kind = gvk.Kind
+	}
+	c.recorder.Record(ctx, verb, kind, client.ObjectKeyFromObject(obj).String())
+}
+
+type dryRunSubResourceClient struct {
+	client.SubResourceClient
+	client      *dryRunClient
+	subResource string
+}
+
+func (sw *dryRunSubResourceClient) Create(ctx context.Context, obj, subResource client.Object, opts ...client.SubResourceCreateOption) error {
+	sw.client.record(ctx, "create/"+sw.subResource, obj)
+	return sw.SubResourceClient.Create(ctx, obj, subResource, opts...)
+}
+
+func (sw *dryRunSubResourceClient) Update(ctx context.Context, obj client.Object, opts ...client.SubResourceUpdateOption) error {
+	sw.client.record(ctx, "update/"+sw.subResource, obj)
+	return sw.SubResourceClient.Update(ctx, obj, opts...)
+}
+
+func (sw *dryRunSubResourceClient) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.SubResourcePatchOption) error {
+	sw.client.record(ctx, "patch/"+sw.subResource, obj)
+	return sw.SubResourceClient.Patch(ctx, obj, patch, opts...)
+}
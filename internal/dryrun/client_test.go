@@ -0,0 +1,50 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dryrun
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestClientDoesNotPersist(t *testing.T) {
+	ctx := context.Background()
+	scheme := runtime.NewScheme()
+	assert.NoError(t, corev1.AddToScheme(scheme))
+	existing := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "existing", Namespace: "default"}}
+	recorder := NewRecorder()
+	c := NewClient(fake.NewClientBuilder().WithScheme(scheme).WithObjects(existing).Build(), recorder)
+
+	created := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "created", Namespace: "default"}}
+	assert.NoError(t, c.Create(ctx, created))
+	assert.NoError(t, c.Delete(ctx, existing))
+
+	var cm corev1.ConfigMap
+	assert.Error(t, c.Get(ctx, client.ObjectKeyFromObject(created), &cm), "created object must not be persisted")
+	assert.NoError(t, c.Get(ctx, client.ObjectKeyFromObject(existing), &cm), "deleted object must be kept")
+
+	summary := recorder.Summary()
+	assert.Equal(t, map[string]int{"create ConfigMap": 1, "delete ConfigMap": 1}, summary.Totals)
+	assert.Equal(t, "default/created", summary.Actions[0].Name)
+}
@@ -0,0 +1,112 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package dryrun records the changes the operator would make instead of performing them.
+package dryrun
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// Action is a change the operator skipped because it runs in dry-run mode.
+type Action struct {
+	Verb      string    `json:"verb"`
+	Kind      string    `json:"kind"`
+	Name      string    `json:"name"`
+	Count     int       `json:"count"`
+	FirstSeen time.Time `json:"firstSeen"`
+	LastSeen  time.Time `json:"lastSeen"`
+}
+
+// Summary is served by the Recorder's HTTP endpoint.
+type Summary struct {
+	// Totals counts the distinct actions per "<verb> <kind>".
+	Totals  map[string]int `json:"totals"`
+	Actions []Action       `json:"actions"`
+}
+
+// Recorder collects the skipped actions. Repeated actions, e.g. from requeued reconciliations, are counted once.
+type Recorder struct {
+	mu      sync.Mutex
+	actions map[actionKey]*Action
+	now     func() time.Time
+}
+
+type actionKey struct {
+	verb, kind, name string
+}
+
+func NewRecorder() *Recorder {
+	return &Recorder{
+		actions: make(map[actionKey]*Action),
+		now:     time.Now,
+	}
+}
+
+// Record logs a skipped action and adds it to the summary.
+func (r *Recorder) Record(ctx context.Context, verb, kind, name string) {
+	log.FromContext(ctx).Info("dry-run: skipping action", "verb", verb, "kind", kind, "name", name)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := r.now()
+	key := actionKey{verb: verb, kind: kind, name: name}
+	action, found := r.actions[key]
+	if !found {
+		action = &Action{Verb: verb, Kind: kind, Name: name, FirstSeen: now}
+		r.actions[key] = action
+	}
+	action.Count++
+	action.LastSeen = now
+}
+
+// Summary returns all recorded actions, sorted by verb, kind and name.
+func (r *Recorder) Summary() Summary {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	summary := Summary{Totals: make(map[string]int), Actions: make([]Action, 0, len(r.actions))}
+	for _, action := range r.actions {
+		summary.Totals[action.Verb+" "+action.Kind]++
+		summary.Actions = append(summary.Actions, *action)
+	}
+	sort.Slice(summary.Actions, func(i, j int) bool {
+		a, b := summary.Actions[i], summary.Actions[j]
+		if a.Verb != b.Verb {
+			return a.Verb < b.Verb
+		}
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+		return a.Name < b.Name
+	})
+	return summary
+}
+
+// ServeHTTP serves the summary as JSON.
+func (r *Recorder) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(r.Summary()); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,49 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dryrun
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecorderSummary(t *testing.T) {
+	ctx := context.Background()
+	recorder := NewRecorder()
+	recorder.Record(ctx, "create", "TagValue", "my-project/team/payments")
+	recorder.Record(ctx, "create", "TagKey", "my-project/team")
+	recorder.Record(ctx, "create", "TagValue", "my-project/team/payments")
+	recorder.Record(ctx, "create", "TagValue", "my-project/team/search")
+
+	summary := recorder.Summary()
+	assert.Equal(t, map[string]int{"create TagKey": 1, "create TagValue": 2}, summary.Totals)
+	assert.Len(t, summary.Actions, 3)
+	assert.Equal(t, "my-project/team", summary.Actions[0].Name)
+	assert.Equal(t, "my-project/team/payments", summary.Actions[1].Name)
+	assert.Equal(t, 2, summary.Actions[1].Count)
+
+	rec := httptest.NewRecorder()
+	recorder.ServeHTTP(rec, httptest.NewRequest("GET", "/dry-run", nil))
+	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+	var served Summary
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &served))
+	assert.Equal(t, summary.Totals, served.Totals)
+}
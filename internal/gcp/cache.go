@@ -0,0 +1,198 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gcp
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	cache "github.com/patrickmn/go-cache"
+	utilcache "k8s.io/apimachinery/pkg/util/cache"
+)
+
+const (
+	// DefaultCacheTTL is how long tag keys, tag values and projects are cached by default.
+	DefaultCacheTTL = 5 * time.Minute
+	// DefaultNegativeCacheTTL is how long missing tag keys and values are cached by default.
+	DefaultNegativeCacheTTL = 30 * time.Second
+
+	CacheTypeMemory = "memory"
+	CacheTypeLRU    = "lru"
+	CacheTypeNone   = "none"
+)
+
+// Cache stores the results of Resource Manager lookups. Implementations must be safe for concurrent use.
+type Cache interface {
+	Get(key string) (any, bool)
+	Set(key string, value any, ttl time.Duration)
+	Delete(key string)
+}
+
+// NewCache creates a cache of the given type, one of CacheTypeMemory, CacheTypeLRU or CacheTypeNone.
+// size bounds the number of entries of the LRU cache.
+func NewCache(cacheType string, size int) (Cache, error) {
+	switch cacheType {
+	case CacheTypeMemory:
+		return NewMemoryCache(), nil
+	case CacheTypeLRU:
+		if size <= 0 {
+			return nil, fmt.Errorf("LRU cache size must be positive, got %d", size)
+		}
+		return NewLRUCache(size), nil
+	case CacheTypeNone:
+		return NewNoCache(), nil
+	default:
+		return nil, fmt.Errorf("unknown cache type %q, expected %s, %s or %s", cacheType, CacheTypeMemory, CacheTypeLRU, CacheTypeNone)
+	}
+}
+
+type memoryCache struct {
+	cache *cache.Cache
+}
+
+// NewMemoryCache creates an unbounded in-memory cache.
+func NewMemoryCache() Cache {
+	return &memoryCache{cache: cache.New(DefaultCacheTTL, DefaultCacheTTL)}
+}
+
+func (c *memoryCache) Get(key string) (any, bool) {
+	return c.cache.Get(key)
+}
+
+func (c *memoryCache) Set(key string, value any, ttl time.Duration) {
+	c.cache.Set(key, value, ttl)
+}
+
+func (c *memoryCache) Delete(key string) {
+	c.cache.Delete(key)
+}
+
+type lruCache struct {
+	cache *utilcache.LRUExpireCache
+}
+
+// NewLRUCache creates an in-memory cache evicting the least recently used entries beyond size.
+func NewLRUCache(size int) Cache {
+	return &lruCache{cache: utilcache.NewLRUExpireCache(size)}
+}
+
+func (c *lruCache) Get(key string) (any, bool) {
+	return c.cache.Get(key)
+}
+
+func (c *lruCache) Set(key string, value any, ttl time.Duration) {
+	c.cache.Add(key, value, ttl)
+}
+
+func (c *lruCache) Delete(key string) {
+	c.cache.Remove(key)
+}
+
+type noCache struct{}
+
+// NewNoCache creates a cache which never stores anything, so every lookup reaches the API.
+func NewNoCache() Cache {
+	return noCache{}
+}
+
+func (noCache) Get(string) (any, bool)         { return nil, false }
+func (noCache) Set(string, any, time.Duration) {}
+func (noCache) Delete(string)                  {}
+
+// notFoundEntry caches a failed lookup of a missing tag key or value.
+type notFoundEntry struct {
+	err error
+}
+
+// cacheKeyTagKey scopes a tag key by its parent, as keys of different parents may share a short name.
+func cacheKeyTagKey(parent string, key string) string {
+	return fmt.Sprintf("key:%s/%s", parent, key)
+}
+
+// cacheKeyTagValue scopes a tag value by the parent of its key.
+func cacheKeyTagValue(parent string, key string, value string) string {
+	return fmt.Sprintf("value:%s/%s/%s", parent, key, value)
+}
+
+// cacheKeyName maps the resource name of a tag key or value to its cache key, so it can be invalidated by name.
+func cacheKeyName(name string) string {
+	return fmt.Sprintf("name:%s", name)
+}
+
+// cacheGet returns a cached tag key or value. A cached lookup of a missing one is found with its original error.
+func (m *tagsManager) cacheGet(cacheKey string) (any, bool, error) {
+	cached, found := m.cache.Get(cacheKey)
+	if !found {
+		return nil, false, nil
+	}
+	if entry, ok := cached.(notFoundEntry); ok {
+		return nil, true, entry.err
+	}
+	return cached, true, nil
+}
+
+// cacheSet stores a tag key or value, which can later be invalidated by its resource name.
+func (m *tagsManager) cacheSet(cacheKey string, name string, value any) {
+	m.cache.Set(cacheKey, value, m.cacheTTL)
+	if name != "" {
+		m.cache.Set(cacheKeyName(name), cacheKey, m.cacheTTL)
+	}
+}
+
+// cacheNotFound remembers that a tag key or value does not exist.
+func (m *tagsManager) cacheNotFound(cacheKey string, err error) {
+	if m.negativeCacheTTL > 0 {
+		m.cache.Set(cacheKey, notFoundEntry{err: err}, m.negativeCacheTTL)
+	}
+}
+
+// Invalidate drops the cached tag key or value with the given resource name, e.g. because it was deleted.
+func (m *tagsManager) Invalidate(name string) {
+	if cacheKey, found := m.cache.Get(cacheKeyName(name)); found {
+		m.cache.Delete(cacheKey.(string))
+	}
+	m.cache.Delete(cacheKeyName(name))
+}
+
+// WarmCache loads all tag keys of parent accepted by include, nil accepting all, and their values into the cache.
+// This replaces a lookup per key and value with a few paginated listings. It returns the number of cached entries.
+func (m *tagsManager) WarmCache(ctx context.Context, parent string, include func(key string) bool) (int, error) {
+	keys, err := m.ListKeys(ctx, parent)
+	if err != nil {
+		return 0, err
+	}
+
+	cached := 0
+	for _, tagKey := range keys {
+		if include != nil && !include(tagKey.ShortName) {
+			continue
+		}
+		values, err := m.ListValues(ctx, tagKey.Name)
+		if err != nil {
+			return cached, err
+		}
+		// the listed parent is used rather than tagKey.Parent, which may hold the project number instead of its ID
+		m.cacheSet(cacheKeyTagKey(parent, tagKey.ShortName), tagKey.Name, tagKey)
+		cached++
+		for _, tagValue := range values {
+			m.cacheSet(cacheKeyTagValue(parent, tagKey.ShortName, tagValue.ShortName), tagValue.Name, tagValue)
+			cached++
+		}
+	}
+	return cached, nil
+}
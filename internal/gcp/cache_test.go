@@ -0,0 +1,256 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gcp
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	resourcemanager "cloud.google.com/go/resourcemanager/apiv3"
+	"cloud.google.com/go/resourcemanager/apiv3/resourcemanagerpb"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/api/option"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// fakeCountingTagValuesServer serves the value "env/prod" of every project and counts the lookups.
+type fakeCountingTagValuesServer struct {
+	resourcemanagerpb.UnimplementedTagValuesServer
+	mu      sync.Mutex
+	lookups map[string]int
+}
+
+func (s *fakeCountingTagValuesServer) GetNamespacedTagValue(ctx context.Context, req *resourcemanagerpb.GetNamespacedTagValueRequest) (*resourcemanagerpb.TagValue, error) {
+	s.mu.Lock()
+	s.lookups[req.Name]++
+	s.mu.Unlock()
+
+	project, rest, _ := strings.Cut(req.Name, "/")
+	if rest != "env/prod" {
+		return nil, status.Error(codes.PermissionDenied, "permission denied or tag value does not exist")
+	}
+	return &resourcemanagerpb.TagValue{
+		Name:           fmt.Sprintf("tagValues/%s-prod", project),
+		ShortName:      "prod",
+		NamespacedName: req.Name,
+	}, nil
+}
+
+func (s *fakeCountingTagValuesServer) ListTagValues(ctx context.Context, req *resourcemanagerpb.ListTagValuesRequest) (*resourcemanagerpb.ListTagValuesResponse, error) {
+	project := strings.TrimPrefix(req.Parent, "tagKeys/")
+	return &resourcemanagerpb.ListTagValuesResponse{TagValues: []*resourcemanagerpb.TagValue{
+		{Name: fmt.Sprintf("tagValues/%s-prod", project), ShortName: "prod", NamespacedName: project + "/env/prod"},
+	}}, nil
+}
+
+// fakeEnvTagKeysServer serves the key "env" of every project.
+type fakeEnvTagKeysServer struct {
+	resourcemanagerpb.UnimplementedTagKeysServer
+}
+
+func (s *fakeEnvTagKeysServer) GetNamespacedTagKey(ctx context.Context, req *resourcemanagerpb.GetNamespacedTagKeyRequest) (*resourcemanagerpb.TagKey, error) {
+	project, key, _ := strings.Cut(req.Name, "/")
+	if key != "env" {
+		return nil, status.Error(codes.PermissionDenied, "permission denied or tag key does not exist")
+	}
+	return &resourcemanagerpb.TagKey{Name: "tagKeys/" + project, ShortName: key, NamespacedName: req.Name}, nil
+}
+
+// fakeListingEnvTagKeysServer serves the key "env" of every project, and lists it along with another key.
+// Listing is denied by fakeEnvTagKeysServer.
+type fakeListingEnvTagKeysServer struct {
+	fakeEnvTagKeysServer
+}
+
+func (s *fakeListingEnvTagKeysServer) ListTagKeys(ctx context.Context, req *resourcemanagerpb.ListTagKeysRequest) (*resourcemanagerpb.ListTagKeysResponse, error) {
+	project := strings.TrimPrefix(req.Parent, "projects/")
+	return &resourcemanagerpb.ListTagKeysResponse{TagKeys: []*resourcemanagerpb.TagKey{
+		{Name: "tagKeys/" + project, ShortName: "env", NamespacedName: project + "/env"},
+		{Name: "tagKeys/" + project + "-other", ShortName: "other", NamespacedName: project + "/other"},
+	}}, nil
+}
+
+func (s *fakeCountingTagValuesServer) count(name string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.lookups[name]
+}
+
+func newCountingTagsManager(t *testing.T, opts ...Option) (*tagsManager, *fakeCountingTagValuesServer) {
+	return newCountingTagsManagerWithKeys(t, &fakeEnvTagKeysServer{}, opts...)
+}
+
+func newCountingTagsManagerWithKeys(t *testing.T, keysServer resourcemanagerpb.TagKeysServer, opts ...Option) (*tagsManager, *fakeCountingTagValuesServer) {
+	lis := bufconn.Listen(bufSize)
+	server := &fakeCountingTagValuesServer{lookups: make(map[string]int)}
+	s := grpc.NewServer()
+	resourcemanagerpb.RegisterTagValuesServer(s, server)
+	resourcemanagerpb.RegisterTagKeysServer(s, keysServer)
+	go func() {
+		if err := s.Serve(lis); err != nil && err != grpc.ErrServerStopped {
+			t.Errorf("Server exited with error: %v", err)
+		}
+	}()
+	t.Cleanup(s.Stop)
+
+	conn, err := grpc.DialContext(context.Background(), "bufnet", grpc.WithContextDialer(func(ctx context.Context, s string) (net.Conn, error) {
+		return bufDialer(lis)
+	}), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	assert.NoError(t, err, "Failed to dial bufnet")
+	t.Cleanup(func() { conn.Close() })
+
+	keysClient, err := resourcemanager.NewTagKeysClient(context.Background(), option.WithGRPCConn(conn))
+	assert.NoError(t, err, "Failed to create TagKeysClient")
+	valuesClient, err := resourcemanager.NewTagValuesClient(context.Background(), option.WithGRPCConn(conn))
+	assert.NoError(t, err, "Failed to create TagValuesClient")
+
+	return NewTagsManager(keysClient, valuesClient, nil, opts...).(*tagsManager), server
+}
+
+func TestCacheScopedByParent(t *testing.T) {
+	ctx := context.Background()
+	m, server := newCountingTagsManager(t)
+
+	a, err := m.GetValue(ctx, "project-a", "env", "prod")
+	assert.NoError(t, err)
+	b, err := m.GetValue(ctx, "project-b", "env", "prod")
+	assert.NoError(t, err)
+	assert.Equal(t, "tagValues/project-a-prod", a.Name)
+	assert.Equal(t, "tagValues/project-b-prod", b.Name, "project B must not get the tag value of project A")
+
+	_, err = m.GetValue(ctx, "project-a", "env", "prod")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, server.count("project-a/env/prod"), "second lookup should be served from the cache")
+}
+
+func TestCacheInvalidate(t *testing.T) {
+	ctx := context.Background()
+	m, server := newCountingTagsManager(t)
+
+	value, err := m.GetValue(ctx, "project-a", "env", "prod")
+	assert.NoError(t, err)
+	m.Invalidate(value.Name)
+
+	_, err = m.GetValue(ctx, "project-a", "env", "prod")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, server.count("project-a/env/prod"), "invalidated value should be looked up again")
+}
+
+func TestNegativeCache(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("enabled", func(t *testing.T) {
+		m, server := newCountingTagsManager(t)
+		for i := 0; i < 2; i++ {
+			_, err := m.GetValue(ctx, "project-a", "env", "dev")
+			assert.True(t, IsNotFound(err), "cached lookup should still report the tag value as missing")
+		}
+		assert.Equal(t, 1, server.count("project-a/env/dev"))
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		m, server := newCountingTagsManager(t, WithNegativeCacheTTL(0))
+		for i := 0; i < 2; i++ {
+			_, err := m.GetValue(ctx, "project-a", "env", "dev")
+			assert.True(t, IsNotFound(err))
+		}
+		assert.Equal(t, 2, server.count("project-a/env/dev"))
+	})
+}
+
+func TestCacheTypes(t *testing.T) {
+	testCases := []struct {
+		name      string
+		cacheType string
+		size      int
+		wantErr   bool
+		stores    bool
+	}{
+		{name: "memory", cacheType: CacheTypeMemory, stores: true},
+		{name: "lru", cacheType: CacheTypeLRU, size: 2, stores: true},
+		{name: "lru without size", cacheType: CacheTypeLRU, wantErr: true},
+		{name: "none", cacheType: CacheTypeNone},
+		{name: "unknown", cacheType: "redis", wantErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			c, err := NewCache(tc.cacheType, tc.size)
+			if tc.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+
+			c.Set("a", 1, time.Minute)
+			got, found := c.Get("a")
+			assert.Equal(t, tc.stores, found)
+			if tc.stores {
+				assert.Equal(t, 1, got)
+			}
+			c.Delete("a")
+			_, found = c.Get("a")
+			assert.False(t, found)
+		})
+	}
+}
+
+func TestLRUCacheEvicts(t *testing.T) {
+	c := NewLRUCache(2)
+	c.Set("a", 1, time.Minute)
+	c.Set("b", 2, time.Minute)
+	c.Get("a")
+	c.Set("c", 3, time.Minute)
+
+	_, found := c.Get("b")
+	assert.False(t, found, "least recently used entry should be evicted")
+	_, found = c.Get("a")
+	assert.True(t, found)
+	_, found = c.Get("c")
+	assert.True(t, found)
+}
+
+func TestWarmCache(t *testing.T) {
+	mgr, server := newCountingTagsManagerWithKeys(t, &fakeListingEnvTagKeysServer{})
+	ctx := context.Background()
+
+	cached, err := mgr.WarmCache(ctx, "projects/a", func(key string) bool { return key == "env" })
+	assert.NoError(t, err)
+	assert.Equal(t, 2, cached)
+
+	value, err := mgr.GetValue(ctx, "a", "env", "prod")
+	assert.NoError(t, err)
+	assert.Equal(t, "tagValues/a-prod", value.Name)
+	key, err := mgr.GetKey(ctx, "a", "env")
+	assert.NoError(t, err)
+	assert.Equal(t, "tagKeys/a", key.Name)
+	assert.Equal(t, 0, server.count("a/env/prod"))
+
+	// other parents are still looked up
+	_, err = mgr.GetValue(ctx, "b", "env", "prod")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, server.count("b/env/prod"))
+}
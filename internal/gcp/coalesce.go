@@ -0,0 +1,91 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gcp
+
+import (
+	"context"
+
+	"cloud.google.com/go/resourcemanager/apiv3/resourcemanagerpb"
+	"golang.org/x/sync/singleflight"
+)
+
+// coalesce runs fn only once for concurrent calls with the same key, all callers receive its result.
+// fn is detached from the cancellation of the caller starting it, as other callers may still wait for it,
+// while every caller stops waiting as soon as its own context is done. Every caller is notified about the
+// tag keys and values fn created, see ContextWithCreationNotifier.
+func coalesce[T any](ctx context.Context, group *singleflight.Group, operation string, key string, fn func(ctx context.Context) (T, error)) (T, error) {
+	leader := false
+	ch := group.DoChan(key, func() (any, error) {
+		leader = true
+		created := &createdTags{}
+		val, err := fn(ContextWithCreationNotifier(context.WithoutCancel(ctx), created))
+		return coalescedResult[T]{val: val, created: created}, err
+	})
+
+	var zero T
+	select {
+	case <-ctx.Done():
+		return zero, ctx.Err()
+	case result := <-ch:
+		if !leader {
+			coalescedCallsTotal.WithLabelValues(operation).Inc()
+		}
+		shared := result.Val.(coalescedResult[T])
+		shared.created.notify(ctx)
+		if result.Err != nil {
+			return zero, result.Err
+		}
+		return shared.val, nil
+	}
+}
+
+// coalescingScope returns the details of a request changing how tag keys and values are created, so only calls
+// creating them the same way are coalesced.
+func coalescingScope(ctx context.Context) string {
+	namespace, _ := ctx.Value(namespaceKey{}).(string)
+	network, _ := ctx.Value(networkKey{}).(string)
+	return namespace + "|" + network
+}
+
+type coalescedResult[T any] struct {
+	val     T
+	created *createdTags
+}
+
+// createdTags records the tag keys and values created by a coalesced call.
+type createdTags struct {
+	keys   []*resourcemanagerpb.TagKey
+	values []*resourcemanagerpb.TagValue
+}
+
+func (c *createdTags) TagKeyCreated(key *resourcemanagerpb.TagKey) {
+	c.keys = append(c.keys, key)
+}
+
+func (c *createdTags) TagValueCreated(value *resourcemanagerpb.TagValue) {
+	c.values = append(c.values, value)
+}
+
+// notify informs the creation notifier of ctx about the recorded tag keys and values.
+func (c *createdTags) notify(ctx context.Context) {
+	for _, key := range c.keys {
+		notifyTagKeyCreated(ctx, key)
+	}
+	for _, value := range c.values {
+		notifyTagValueCreated(ctx, value)
+	}
+}
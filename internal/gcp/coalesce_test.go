@@ -0,0 +1,209 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gcp
+
+import (
+	"context"
+	"net"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/longrunning/autogen/longrunningpb"
+	resourcemanager "cloud.google.com/go/resourcemanager/apiv3"
+	"cloud.google.com/go/resourcemanager/apiv3/resourcemanagerpb"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/sync/singleflight"
+	"google.golang.org/api/option"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+// fakeRacingTagKeysServer creates tag keys slowly, so concurrent callers overlap.
+// With alreadyExists set, creations fail as if another replica created the key first.
+type fakeRacingTagKeysServer struct {
+	resourcemanagerpb.UnimplementedTagKeysServer
+	alreadyExists bool
+	created       atomic.Bool
+	creations     atomic.Int32
+}
+
+var racingTagKey = &resourcemanagerpb.TagKey{
+	Name:           "tagKeys/123",
+	Parent:         "projects/test-project",
+	ShortName:      "squad",
+	NamespacedName: "test-project/squad",
+}
+
+func (s *fakeRacingTagKeysServer) GetNamespacedTagKey(ctx context.Context, req *resourcemanagerpb.GetNamespacedTagKeyRequest) (*resourcemanagerpb.TagKey, error) {
+	if s.created.Load() {
+		return racingTagKey, nil
+	}
+	return nil, status.Error(codes.PermissionDenied, "permission denied or tag key does not exist")
+}
+
+func (s *fakeRacingTagKeysServer) ListTagKeys(ctx context.Context, req *resourcemanagerpb.ListTagKeysRequest) (*resourcemanagerpb.ListTagKeysResponse, error) {
+	if s.created.Load() {
+		return &resourcemanagerpb.ListTagKeysResponse{TagKeys: []*resourcemanagerpb.TagKey{racingTagKey}}, nil
+	}
+	return &resourcemanagerpb.ListTagKeysResponse{}, nil
+}
+
+func (s *fakeRacingTagKeysServer) CreateTagKey(ctx context.Context, req *resourcemanagerpb.CreateTagKeyRequest) (*longrunningpb.Operation, error) {
+	s.creations.Add(1)
+	time.Sleep(100 * time.Millisecond)
+	s.created.Store(true)
+	if s.alreadyExists {
+		return nil, status.Error(codes.AlreadyExists, "tag key already exists")
+	}
+	response, err := anypb.New(racingTagKey)
+	if err != nil {
+		return nil, err
+	}
+	return &longrunningpb.Operation{
+		Name:   "operations/create-key",
+		Done:   true,
+		Result: &longrunningpb.Operation_Response{Response: response},
+	}, nil
+}
+
+func newRacingTagsManager(t *testing.T, server *fakeRacingTagKeysServer) TagsManager {
+	lis := bufconn.Listen(bufSize)
+	s := grpc.NewServer()
+	resourcemanagerpb.RegisterTagKeysServer(s, server)
+	go func() {
+		if err := s.Serve(lis); err != nil && err != grpc.ErrServerStopped {
+			t.Errorf("Server exited with error: %v", err)
+		}
+	}()
+	t.Cleanup(s.Stop)
+
+	conn, err := grpc.DialContext(context.Background(), "bufnet", grpc.WithContextDialer(func(ctx context.Context, s string) (net.Conn, error) {
+		return bufDialer(lis)
+	}), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	assert.NoError(t, err, "Failed to dial bufnet")
+	t.Cleanup(func() { conn.Close() })
+
+	keysClient, err := resourcemanager.NewTagKeysClient(context.Background(), option.WithGRPCConn(conn))
+	assert.NoError(t, err, "Failed to create TagKeysClient")
+	return NewTagsManager(keysClient, nil, nil)
+}
+
+func TestEnsureKeyCoalescesConcurrentCalls(t *testing.T) {
+	server := &fakeRacingTagKeysServer{}
+	mgr := newRacingTagsManager(t, server)
+	coalescedBefore := testutil.ToFloat64(coalescedCallsTotal.WithLabelValues("ensure_key"))
+
+	const callers = 10
+	var wg sync.WaitGroup
+	keys := make([]*resourcemanagerpb.TagKey, callers)
+	errs := make([]error, callers)
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			keys[i], errs[i] = mgr.EnsureKey(context.Background(), "test-project", "squad")
+		}(i)
+	}
+	wg.Wait()
+
+	for i := 0; i < callers; i++ {
+		assert.NoError(t, errs[i])
+		assert.Equal(t, "tagKeys/123", keys[i].Name)
+	}
+	assert.Equal(t, int32(1), server.creations.Load(), "the tag key should be created only once")
+	assert.Equal(t, float64(callers-1), testutil.ToFloat64(coalescedCallsTotal.WithLabelValues("ensure_key"))-coalescedBefore)
+}
+
+func TestCreateKeyTreatsAlreadyExistsAsSuccess(t *testing.T) {
+	server := &fakeRacingTagKeysServer{alreadyExists: true}
+	mgr := newRacingTagsManager(t, server)
+	alreadyExistsBefore := testutil.ToFloat64(tagAlreadyExistsTotal.WithLabelValues("TagKey"))
+
+	key, err := mgr.EnsureKey(context.Background(), "test-project", "squad")
+	assert.NoError(t, err)
+	assert.Equal(t, "tagKeys/123", key.Name)
+	assert.Equal(t, float64(1), testutil.ToFloat64(tagAlreadyExistsTotal.WithLabelValues("TagKey"))-alreadyExistsBefore)
+}
+
+func TestCoalesceStopsWaitingOnCancellation(t *testing.T) {
+	var group singleflight.Group
+	release := make(chan struct{})
+	detached := make(chan error, 1)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err := coalesce(ctx, &group, "test", "key", func(ctx context.Context) (int, error) {
+		<-release
+		detached <- ctx.Err()
+		return 1, nil
+	})
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	close(release)
+	assert.NoError(t, <-detached, "the shared call must not be cancelled with the caller which started it")
+}
+
+func TestEnsureKeyNotifiesEveryCoalescedCaller(t *testing.T) {
+	server := &fakeRacingTagKeysServer{}
+	mgr := newRacingTagsManager(t, server)
+
+	const callers = 5
+	var wg sync.WaitGroup
+	notifiers := make([]*recordingNotifier, callers)
+	for i := 0; i < callers; i++ {
+		notifiers[i] = &recordingNotifier{}
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, err := mgr.EnsureKey(ContextWithCreationNotifier(context.Background(), notifiers[i]), "test-project", "squad")
+			assert.NoError(t, err)
+		}(i)
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(1), server.creations.Load(), "the tag key should be created only once")
+	for i := 0; i < callers; i++ {
+		if assert.Len(t, notifiers[i].keys, 1) {
+			assert.Equal(t, "tagKeys/123", notifiers[i].keys[0].Name)
+		}
+	}
+}
+
+func TestEnsureKeyDoesNotCoalesceCallsOfDifferentNamespaces(t *testing.T) {
+	server := &fakeRacingTagKeysServer{}
+	mgr := newRacingTagsManager(t, server)
+
+	var wg sync.WaitGroup
+	for _, namespace := range []string{"payments", "checkout"} {
+		wg.Add(1)
+		go func(namespace string) {
+			defer wg.Done()
+			_, err := mgr.EnsureKey(ContextWithNamespace(context.Background(), namespace), "test-project", "squad")
+			assert.NoError(t, err)
+		}(namespace)
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(2), server.creations.Load(), "each namespace describes the tag key it creates")
+}
@@ -0,0 +1,114 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gcp
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"strings"
+
+	resourcemanager "cloud.google.com/go/resourcemanager/apiv3"
+	"cloud.google.com/go/resourcemanager/apiv3/resourcemanagerpb"
+	"google.golang.org/protobuf/proto"
+)
+
+const placeholderPrefix = "dry-run-"
+
+// ActionRecorder records the changes a dry-run TagsManager skipped.
+type ActionRecorder interface {
+	Record(ctx context.Context, verb, kind, name string)
+}
+
+// WithDryRun makes the TagsManager record its changes to tag keys and values with recorder instead of making them.
+// Everything else, like lookups and ownership checks, works as usual. Missing tag keys and values are answered with
+// placeholders, so callers can plan the bindings they would create.
+func WithDryRun(recorder ActionRecorder) Option {
+	return func(m *tagsManager) {
+		m.writer = &dryRunTagWriter{recorder: recorder, keysClient: m.keysClient}
+	}
+}
+
+// dryRunTagWriter records changes instead of making them. It only reads IAM policies, to skip grants which exist.
+type dryRunTagWriter struct {
+	recorder   ActionRecorder
+	keysClient *resourcemanager.TagKeysClient
+}
+
+func (w *dryRunTagWriter) createKey(ctx context.Context, tagKey *resourcemanagerpb.TagKey) (*resourcemanagerpb.TagKey, string, error) {
+	namespacedName := fmt.Sprintf("%s/%s", tagNamespace(tagKey.Parent), tagKey.ShortName)
+	created := proto.Clone(tagKey).(*resourcemanagerpb.TagKey)
+	created.Name = "tagKeys/" + placeholderID(namespacedName)
+	created.NamespacedName = namespacedName
+
+	w.recorder.Record(ctx, "create", KindTagKey, namespacedName)
+	return created, "", nil
+}
+
+func (w *dryRunTagWriter) createValue(ctx context.Context, tagKey *resourcemanagerpb.TagKey, tagValue *resourcemanagerpb.TagValue) (*resourcemanagerpb.TagValue, string, error) {
+	namespacedName := fmt.Sprintf("%s/%s", tagKey.NamespacedName, tagValue.ShortName)
+	created := proto.Clone(tagValue).(*resourcemanagerpb.TagValue)
+	created.Name = "tagValues/" + placeholderID(namespacedName)
+	created.NamespacedName = namespacedName
+
+	w.recorder.Record(ctx, "create", KindTagValue, namespacedName)
+	return created, "", nil
+}
+
+func (w *dryRunTagWriter) updateKey(ctx context.Context, tagKey *resourcemanagerpb.TagKey) (*resourcemanagerpb.TagKey, error) {
+	w.recorder.Record(ctx, "update", KindTagKey, tagKey.NamespacedName)
+	return tagKey, nil
+}
+
+func (w *dryRunTagWriter) updateValue(ctx context.Context, tagValue *resourcemanagerpb.TagValue) (*resourcemanagerpb.TagValue, error) {
+	w.recorder.Record(ctx, "update", KindTagValue, tagValue.NamespacedName)
+	return tagValue, nil
+}
+
+func (w *dryRunTagWriter) deleteKey(ctx context.Context, name string) (string, error) {
+	w.recorder.Record(ctx, "delete", KindTagKey, name)
+	return "", nil
+}
+
+func (w *dryRunTagWriter) deleteValue(ctx context.Context, name string) (string, error) {
+	w.recorder.Record(ctx, "delete", KindTagValue, name)
+	return "", nil
+}
+
+func (w *dryRunTagWriter) addTagUser(ctx context.Context, tagKey *resourcemanagerpb.TagKey, principal string) error {
+	// placeholders of tag keys which would be created have no policy yet
+	if !isPlaceholder(tagKey.Name) {
+		if _, changed, err := tagUserPolicy(ctx, w.keysClient, tagKey.Name, principal); err != nil || !changed {
+			return err
+		}
+	}
+	w.recorder.Record(ctx, "grant "+TagUserRole, KindTagKey, tagKey.NamespacedName+" to "+principal)
+	return nil
+}
+
+// isPlaceholder reports whether name is the placeholder name of a tag key or value which would be created.
+func isPlaceholder(name string) bool {
+	_, id, _ := strings.Cut(name, "/")
+	return strings.HasPrefix(id, placeholderPrefix)
+}
+
+// placeholderID derives a stable ID for a tag that does not exist yet, so planned bindings keep their names.
+func placeholderID(namespacedName string) string {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(namespacedName))
+	return fmt.Sprintf("%s%08x", placeholderPrefix, h.Sum32())
+}
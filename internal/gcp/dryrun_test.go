@@ -0,0 +1,184 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gcp
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	resourcemanager "cloud.google.com/go/resourcemanager/apiv3"
+	"cloud.google.com/go/resourcemanager/apiv3/resourcemanagerpb"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/api/option"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// fakeDryRunTagKeysServer fails on any creation, as dry-run must never create tags.
+type fakeDryRunTagKeysServer struct {
+	resourcemanagerpb.UnimplementedTagKeysServer
+}
+
+func (s *fakeDryRunTagKeysServer) GetNamespacedTagKey(ctx context.Context, req *resourcemanagerpb.GetNamespacedTagKeyRequest) (*resourcemanagerpb.TagKey, error) {
+	if req.Name == "test-project/existing-key" {
+		return &resourcemanagerpb.TagKey{
+			Name:           "tagKeys/123",
+			ShortName:      "existing-key",
+			NamespacedName: "test-project/existing-key",
+		}, nil
+	}
+	return nil, status.Error(codes.PermissionDenied, "permission denied or tag key does not exist")
+}
+
+func (s *fakeDryRunTagKeysServer) ListTagKeys(ctx context.Context, req *resourcemanagerpb.ListTagKeysRequest) (*resourcemanagerpb.ListTagKeysResponse, error) {
+	return &resourcemanagerpb.ListTagKeysResponse{TagKeys: []*resourcemanagerpb.TagKey{
+		{Name: "tagKeys/123", ShortName: "existing-key", NamespacedName: "test-project/existing-key"},
+	}}, nil
+}
+
+func (s *fakeDryRunTagKeysServer) GetTagKey(ctx context.Context, req *resourcemanagerpb.GetTagKeyRequest) (*resourcemanagerpb.TagKey, error) {
+	return &resourcemanagerpb.TagKey{Name: req.Name, Description: OwnershipMarker}, nil
+}
+
+type fakeDryRunTagValuesServer struct {
+	resourcemanagerpb.UnimplementedTagValuesServer
+}
+
+func (s *fakeDryRunTagValuesServer) GetNamespacedTagValue(ctx context.Context, req *resourcemanagerpb.GetNamespacedTagValueRequest) (*resourcemanagerpb.TagValue, error) {
+	if req.Name == "test-project/existing-key/existing-value" {
+		return &resourcemanagerpb.TagValue{
+			Name:           "tagValues/456",
+			ShortName:      "existing-value",
+			NamespacedName: "test-project/existing-key/existing-value",
+		}, nil
+	}
+	return nil, status.Error(codes.PermissionDenied, "permission denied or tag value does not exist")
+}
+
+func (s *fakeDryRunTagValuesServer) ListTagValues(ctx context.Context, req *resourcemanagerpb.ListTagValuesRequest) (*resourcemanagerpb.ListTagValuesResponse, error) {
+	return &resourcemanagerpb.ListTagValuesResponse{TagValues: []*resourcemanagerpb.TagValue{
+		{Name: "tagValues/456", ShortName: "existing-value", NamespacedName: "test-project/existing-key/existing-value"},
+	}}, nil
+}
+
+func (s *fakeDryRunTagValuesServer) GetTagValue(ctx context.Context, req *resourcemanagerpb.GetTagValueRequest) (*resourcemanagerpb.TagValue, error) {
+	return &resourcemanagerpb.TagValue{Name: req.Name, Description: OwnershipMarker}, nil
+}
+
+type recordedAction struct {
+	verb, kind, name string
+}
+
+type fakeActionRecorder struct {
+	actions []recordedAction
+}
+
+func (r *fakeActionRecorder) Record(_ context.Context, verb, kind, name string) {
+	r.actions = append(r.actions, recordedAction{verb: verb, kind: kind, name: name})
+}
+
+// newDryRunTestTagsManager creates a dry-run TagsManager backed by servers which do not implement any changes.
+func newDryRunTestTagsManager(t *testing.T, recorder ActionRecorder, opts ...Option) TagsManager {
+	lis := bufconn.Listen(bufSize)
+
+	s := grpc.NewServer()
+	resourcemanagerpb.RegisterTagKeysServer(s, &fakeDryRunTagKeysServer{})
+	resourcemanagerpb.RegisterTagValuesServer(s, &fakeDryRunTagValuesServer{})
+
+	go func() {
+		if err := s.Serve(lis); err != nil && err != grpc.ErrServerStopped {
+			t.Errorf("Server exited with error: %v", err)
+		}
+	}()
+	t.Cleanup(s.Stop)
+
+	conn, err := grpc.DialContext(context.Background(), "bufnet", grpc.WithContextDialer(func(ctx context.Context, s string) (net.Conn, error) {
+		return bufDialer(lis)
+	}), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	assert.NoError(t, err, "Failed to dial bufnet")
+	t.Cleanup(func() { conn.Close() })
+
+	keysClient, err := resourcemanager.NewTagKeysClient(context.Background(), option.WithGRPCConn(conn))
+	assert.NoError(t, err, "Failed to create TagKeysClient")
+	valuesClient, err := resourcemanager.NewTagValuesClient(context.Background(), option.WithGRPCConn(conn))
+	assert.NoError(t, err, "Failed to create TagValuesClient")
+
+	return NewTagsManager(keysClient, valuesClient, nil, append(opts, WithDryRun(recorder))...)
+}
+
+func TestDryRunTagsManagerWithFakeGRPCServer(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	recorder := &fakeActionRecorder{}
+	mgr := newDryRunTestTagsManager(t, recorder)
+
+	value, err := mgr.EnsureValue(ctx, "test-project", "existing-key", "existing-value")
+	assert.NoError(t, err)
+	assert.Equal(t, "tagValues/456", value.Name)
+	assert.Empty(t, recorder.actions)
+
+	value, err = mgr.EnsureValue(ctx, "test-project", "existing-key", "new-value")
+	assert.NoError(t, err)
+	assert.Equal(t, "tagKeys/123", value.Parent)
+	assert.Equal(t, "test-project/existing-key/new-value", value.NamespacedName)
+	assert.Equal(t, []recordedAction{{"create", "TagValue", "test-project/existing-key/new-value"}}, recorder.actions)
+
+	recorder.actions = nil
+	value, err = mgr.EnsureValue(ctx, "test-project", "new-key", "new-value")
+	assert.NoError(t, err)
+	assert.Equal(t, "test-project/new-key/new-value", value.NamespacedName)
+	assert.Equal(t, []recordedAction{
+		{"create", "TagKey", "test-project/new-key"},
+		{"create", "TagValue", "test-project/new-key/new-value"},
+	}, recorder.actions)
+
+	again, err := mgr.EnsureValue(ctx, "test-project", "new-key", "new-value")
+	assert.NoError(t, err)
+	assert.Equal(t, value.Name, again.Name, "placeholder names must be stable")
+
+	recorder.actions = nil
+	deleted, err := mgr.DeleteValueIfUnused(ctx, "test-project", "tagKeys/123", "tagValues/456")
+	assert.NoError(t, err)
+	assert.True(t, deleted)
+	assert.NoError(t, mgr.DeleteKeyIfUnused(ctx, "test-project", "tagKeys/123"))
+	assert.Equal(t, []recordedAction{
+		{"delete", "TagValue", "tagValues/456"},
+		{"delete", "TagKey", "tagKeys/123"},
+	}, recorder.actions)
+}
+
+func TestDryRunTagsManagerRecordsAdoptions(t *testing.T) {
+	recorder := &fakeActionRecorder{}
+	mgr := newDryRunTestTagsManager(t, recorder, WithAdoptExisting(true))
+
+	key, err := mgr.EnsureKey(context.Background(), "test-project", "existing-key")
+	assert.NoError(t, err)
+	assert.True(t, IsOwned(key.Description))
+	value, err := mgr.EnsureValue(context.Background(), "test-project", "existing-key", "existing-value")
+	assert.NoError(t, err)
+	assert.Equal(t, "tagValues/456", value.Name)
+	assert.Equal(t, []recordedAction{
+		{"update", "TagKey", "test-project/existing-key"},
+		{"update", "TagValue", "test-project/existing-key/existing-value"},
+	}, recorder.actions)
+}
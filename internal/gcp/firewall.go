@@ -0,0 +1,147 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gcp
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"cloud.google.com/go/resourcemanager/apiv3/resourcemanagerpb"
+)
+
+// networkPurposeDataKey is the purpose data entry of GCE_FIREWALL tag keys naming their VPC network.
+const networkPurposeDataKey = "network"
+
+type networkKey struct{}
+
+// ContextWithNetwork returns a context recording the VPC network of the resource a request is served for.
+// Firewall tag keys configured without a network are created for it.
+func ContextWithNetwork(ctx context.Context, network string) context.Context {
+	return context.WithValue(ctx, networkKey{}, network)
+}
+
+// WithFirewallKeys creates the given tag keys with the GCE_FIREWALL purpose, as secure tags for Cloud NGFW.
+// The keys map to the VPC network the tags apply to, an empty network is taken from the context, see ContextWithNetwork.
+func WithFirewallKeys(keys map[string]string) Option {
+	return func(m *tagsManager) {
+		m.firewallKeys = keys
+	}
+}
+
+// ParseFirewallKeys parses a comma separated list of tag keys, each optionally followed by =network.
+func ParseFirewallKeys(in string) (map[string]string, error) {
+	keys := make(map[string]string)
+	for _, entry := range strings.Split(in, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		key, network, found := strings.Cut(entry, "=")
+		if key == "" || found && network == "" {
+			return nil, fmt.Errorf("invalid firewall tag key %q: must be key or key=network", entry)
+		}
+		if network != "" {
+			if _, err := NetworkID("", network); err != nil {
+				return nil, err
+			}
+		}
+		keys[key] = network
+	}
+	return keys, nil
+}
+
+// NetworkID returns a VPC network in the "<project>/<network>" form expected in the purpose data of tag keys.
+// network is either a network name in projectID, "<project>/<network>", "projects/<project>/global/networks/<network>"
+// or the self link of the network.
+func NetworkID(projectID string, network string) (string, error) {
+	name := network
+	if _, path, found := strings.Cut(name, "/compute/"); found && strings.HasPrefix(name, "https://") {
+		// strip the API version of self links
+		_, name, _ = strings.Cut(path, "/")
+	}
+	parts := strings.Split(name, "/")
+	switch {
+	case len(parts) == 1 && parts[0] != "" && projectID != "":
+		return projectID + "/" + parts[0], nil
+	case len(parts) == 2 && parts[0] != "" && parts[1] != "":
+		return name, nil
+	case len(parts) == 5 && parts[0] == "projects" && parts[1] != "" && parts[2] == "global" && parts[3] == "networks" && parts[4] != "":
+		return parts[1] + "/" + parts[4], nil
+	}
+	return "", fmt.Errorf("invalid network %q: must be <project>/<network>, projects/<project>/global/networks/<network> or a self link", network)
+}
+
+// IsFirewallKey reports whether tag values of the key are secure tags, which can only be bound to VM instances.
+func IsFirewallKey(tagKey *resourcemanagerpb.TagKey) bool {
+	return tagKey.Purpose == resourcemanagerpb.Purpose_GCE_FIREWALL
+}
+
+// FirewallNetwork reports whether the tag key has, or will be created with, the GCE_FIREWALL purpose, and the VPC network
+// it applies to. The network is empty if the key does not exist yet and its network is neither configured nor in the context.
+func (m *tagsManager) FirewallNetwork(ctx context.Context, projectID string, key string) (string, bool, error) {
+	_, configured := m.firewallKeys[key]
+	tagKey, err := m.GetKey(ctx, projectID, key)
+	if IsNotFound(err) {
+		if !configured {
+			return "", false, nil
+		}
+		network, err := m.firewallNetwork(ctx, projectID, key)
+		return network, true, err
+	}
+	if err != nil {
+		return "", false, err
+	}
+	if !IsFirewallKey(tagKey) {
+		if configured {
+			// the purpose of a tag key cannot be changed
+			return "", false, fmt.Errorf("firewall tag key %s exists without the GCE_FIREWALL purpose", tagKey.NamespacedName)
+		}
+		return "", false, nil
+	}
+	network, err := NetworkID("", tagKey.PurposeData[networkPurposeDataKey])
+	return network, true, err
+}
+
+// firewallNetwork returns the VPC network a configured firewall tag key is created for, if known.
+func (m *tagsManager) firewallNetwork(ctx context.Context, projectID string, key string) (string, error) {
+	network := m.firewallKeys[key]
+	if network == "" {
+		network, _ = ctx.Value(networkKey{}).(string)
+	}
+	if network == "" {
+		return "", nil
+	}
+	return NetworkID(projectID, network)
+}
+
+// setPurpose adds the GCE_FIREWALL purpose to a tag key about to be created, if configured.
+func (m *tagsManager) setPurpose(ctx context.Context, projectID string, tagKey *resourcemanagerpb.TagKey) error {
+	if _, configured := m.firewallKeys[tagKey.ShortName]; !configured {
+		return nil
+	}
+	network, err := m.firewallNetwork(ctx, projectID, tagKey.ShortName)
+	if err != nil {
+		return err
+	}
+	if network == "" {
+		return fmt.Errorf("network of firewall tag key %s is unknown", tagKey.ShortName)
+	}
+	tagKey.Purpose = resourcemanagerpb.Purpose_GCE_FIREWALL
+	tagKey.PurposeData = map[string]string{networkPurposeDataKey: network}
+	return nil
+}
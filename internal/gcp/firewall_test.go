@@ -0,0 +1,217 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gcp
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"cloud.google.com/go/longrunning/autogen/longrunningpb"
+	resourcemanager "cloud.google.com/go/resourcemanager/apiv3"
+	"cloud.google.com/go/resourcemanager/apiv3/resourcemanagerpb"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/api/option"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/proto"
+)
+
+// fakeFirewallTagKeysServer serves the tag keys "fw-role", a secure tag key, and "team", a generic one.
+// It records the tag keys it is asked to create.
+type fakeFirewallTagKeysServer struct {
+	resourcemanagerpb.UnimplementedTagKeysServer
+	created []*resourcemanagerpb.TagKey
+}
+
+var firewallTagKeys = map[string]*resourcemanagerpb.TagKey{
+	"test-project/fw-role": {
+		Name:           "tagKeys/1",
+		ShortName:      "fw-role",
+		NamespacedName: "test-project/fw-role",
+		Purpose:        resourcemanagerpb.Purpose_GCE_FIREWALL,
+		PurposeData:    map[string]string{"network": "https://www.googleapis.com/compute/v1/projects/net-project/global/networks/prod"},
+	},
+	"test-project/team": {Name: "tagKeys/2", ShortName: "team", NamespacedName: "test-project/team"},
+}
+
+func (s *fakeFirewallTagKeysServer) GetNamespacedTagKey(ctx context.Context, req *resourcemanagerpb.GetNamespacedTagKeyRequest) (*resourcemanagerpb.TagKey, error) {
+	if tagKey, ok := firewallTagKeys[req.Name]; ok {
+		return tagKey, nil
+	}
+	return nil, status.Error(codes.PermissionDenied, "permission denied or tag key does not exist")
+}
+
+func (s *fakeFirewallTagKeysServer) ListTagKeys(ctx context.Context, req *resourcemanagerpb.ListTagKeysRequest) (*resourcemanagerpb.ListTagKeysResponse, error) {
+	return &resourcemanagerpb.ListTagKeysResponse{}, nil
+}
+
+func (s *fakeFirewallTagKeysServer) CreateTagKey(ctx context.Context, req *resourcemanagerpb.CreateTagKeyRequest) (*longrunningpb.Operation, error) {
+	s.created = append(s.created, req.TagKey)
+	created := proto.Clone(req.TagKey).(*resourcemanagerpb.TagKey)
+	created.Name = "tagKeys/3"
+	return doneOperation(created)
+}
+
+func newFirewallTagsManager(t *testing.T, server *fakeFirewallTagKeysServer, opts ...Option) TagsManager {
+	lis := bufconn.Listen(bufSize)
+	s := grpc.NewServer()
+	resourcemanagerpb.RegisterTagKeysServer(s, server)
+	go func() {
+		if err := s.Serve(lis); err != nil && err != grpc.ErrServerStopped {
+			t.Errorf("Server exited with error: %v", err)
+		}
+	}()
+	t.Cleanup(s.Stop)
+
+	conn, err := grpc.DialContext(context.Background(), "bufnet", grpc.WithContextDialer(func(ctx context.Context, s string) (net.Conn, error) {
+		return bufDialer(lis)
+	}), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	assert.NoError(t, err, "Failed to dial bufnet")
+	t.Cleanup(func() { conn.Close() })
+
+	keysClient, err := resourcemanager.NewTagKeysClient(context.Background(), option.WithGRPCConn(conn))
+	assert.NoError(t, err, "Failed to create TagKeysClient")
+	return NewTagsManager(keysClient, nil, nil, opts...)
+}
+
+func TestCreateFirewallKey(t *testing.T) {
+	server := &fakeFirewallTagKeysServer{}
+	mgr := newFirewallTagsManager(t, server, WithFirewallKeys(map[string]string{
+		"fw-app": "net-project/prod",
+		"fw-db":  "",
+	}))
+	ctx := context.Background()
+
+	tagKey, err := mgr.EnsureKey(ctx, "test-project", "fw-app")
+	assert.NoError(t, err)
+	assert.True(t, IsFirewallKey(tagKey))
+	assert.Equal(t, map[string]string{"network": "net-project/prod"}, tagKey.PurposeData)
+
+	_, err = mgr.EnsureKey(ctx, "test-project", "fw-db")
+	assert.ErrorContains(t, err, "network of firewall tag key fw-db is unknown")
+
+	tagKey, err = mgr.EnsureKey(ContextWithNetwork(ctx, "dev"), "test-project", "fw-db")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"network": "test-project/dev"}, tagKey.PurposeData)
+
+	tagKey, err = mgr.EnsureKey(ctx, "test-project", "env")
+	assert.NoError(t, err)
+	assert.False(t, IsFirewallKey(tagKey))
+	assert.Len(t, server.created, 3)
+}
+
+func TestFirewallNetwork(t *testing.T) {
+	testCases := []struct {
+		name         string
+		key          string
+		firewallKeys map[string]string
+		wantNetwork  string
+		wantFirewall bool
+		wantErr      bool
+	}{
+		{
+			name:         "existing secure tag key",
+			key:          "fw-role",
+			wantNetwork:  "net-project/prod",
+			wantFirewall: true,
+		},
+		{
+			name: "existing generic tag key",
+			key:  "team",
+		},
+		{
+			name:         "generic tag key configured as secure tag key",
+			key:          "team",
+			firewallKeys: map[string]string{"team": "net-project/prod"},
+			wantErr:      true,
+		},
+		{
+			name:         "missing secure tag key",
+			key:          "fw-app",
+			firewallKeys: map[string]string{"fw-app": "projects/net-project/global/networks/dev"},
+			wantNetwork:  "net-project/dev",
+			wantFirewall: true,
+		},
+		{
+			name:         "missing secure tag key without network",
+			key:          "fw-app",
+			firewallKeys: map[string]string{"fw-app": ""},
+			wantFirewall: true,
+		},
+		{
+			name: "missing generic tag key",
+			key:  "env",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mgr := newFirewallTagsManager(t, &fakeFirewallTagKeysServer{}, WithFirewallKeys(tc.firewallKeys))
+			network, firewall, err := mgr.FirewallNetwork(context.Background(), "test-project", tc.key)
+			if tc.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.wantNetwork, network)
+			assert.Equal(t, tc.wantFirewall, firewall)
+		})
+	}
+}
+
+func TestNetworkID(t *testing.T) {
+	testCases := []struct {
+		name    string
+		network string
+		want    string
+		wantErr bool
+	}{
+		{name: "name", network: "prod", want: "test-project/prod"},
+		{name: "project and name", network: "net-project/prod", want: "net-project/prod"},
+		{name: "resource name", network: "projects/net-project/global/networks/prod", want: "net-project/prod"},
+		{name: "self link", network: "https://www.googleapis.com/compute/v1/projects/net-project/global/networks/prod", want: "net-project/prod"},
+		{name: "empty", network: "", wantErr: true},
+		{name: "subnetwork", network: "projects/net-project/regions/europe-west1/subnetworks/prod", wantErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := NetworkID("test-project", tc.network)
+			if tc.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestParseFirewallKeys(t *testing.T) {
+	got, err := ParseFirewallKeys("fw-role=net-project/prod, fw-app")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"fw-role": "net-project/prod", "fw-app": ""}, got)
+
+	_, err = ParseFirewallKeys("fw-role=")
+	assert.Error(t, err)
+	_, err = ParseFirewallKeys("fw-role=prod")
+	assert.Error(t, err, "networks must name their project")
+}
@@ -0,0 +1,91 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gcp
+
+import (
+	"context"
+	"fmt"
+
+	"cloud.google.com/go/resourcemanager/apiv3/resourcemanagerpb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// TagUserRole allows to bind the values of a tag key. It is granted on the tag keys created by the operator, see WithTagUser.
+const TagUserRole = "roles/resourcemanager.tagUser"
+
+// maxIAMPolicyAttempts limits how often the IAM policy of a tag key is read and written again after concurrent changes.
+const maxIAMPolicyAttempts = 3
+
+type tagUserKey struct{}
+
+// ContextWithTagUser returns a context recording the principal binding the tags of the resource a request is served for,
+// e.g. the service account of Config Connector in the namespace of the resource. It takes precedence over WithTagUser.
+func ContextWithTagUser(ctx context.Context, principal string) context.Context {
+	return context.WithValue(ctx, tagUserKey{}, principal)
+}
+
+// WithTagUser grants TagUserRole on the tag keys created by the operator to principal, e.g.
+// "serviceAccount:cnrm-system@project.iam.gserviceaccount.com", so Config Connector can bind their values.
+func WithTagUser(principal string) Option {
+	return func(m *tagsManager) {
+		m.tagUser = principal
+	}
+}
+
+// tagUserFor returns the principal TagUserRole is granted to for the request, if any.
+func (m *tagsManager) tagUserFor(ctx context.Context) string {
+	if principal, ok := ctx.Value(tagUserKey{}).(string); ok && principal != "" {
+		return principal
+	}
+	return m.tagUser
+}
+
+// grantTagUser makes sure the principal binding tags holds TagUserRole on the key of tagValue, if the key is owned
+// by the operator. Grants are remembered, so the IAM policy of a key is changed at most once per principal.
+func (m *tagsManager) grantTagUser(ctx context.Context, projectID string, key string, tagValue *resourcemanagerpb.TagValue) error {
+	principal := m.tagUserFor(ctx)
+	if principal == "" {
+		return nil
+	}
+	grant := tagValue.Parent + "|" + principal
+	if _, granted := m.tagUserGrants.Load(grant); granted {
+		return nil
+	}
+
+	tagKey, err := m.GetKey(ctx, projectID, key)
+	if err != nil {
+		return err
+	}
+	if !IsOwned(tagKey.Description) {
+		return nil
+	}
+	for attempt := 1; ; attempt++ {
+		err = m.writer.addTagUser(ctx, tagKey, principal)
+		// a concurrent change of the policy invalidates its etag, so it has to be read again
+		if status.Code(err) != codes.Aborted || attempt == maxIAMPolicyAttempts {
+			break
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("failed to grant %s on tag key %s to %s: %w", TagUserRole, tagKey.NamespacedName, principal, err)
+	}
+	log.FromContext(ctx).Info("granted tag user role", "tagKey", tagKey.NamespacedName, "principal", principal)
+	m.tagUserGrants.Store(grant, true)
+	return nil
+}
@@ -0,0 +1,166 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gcp
+
+import (
+	"context"
+	"net"
+	"sync"
+	"testing"
+
+	"cloud.google.com/go/iam/apiv1/iampb"
+	resourcemanager "cloud.google.com/go/resourcemanager/apiv3"
+	"cloud.google.com/go/resourcemanager/apiv3/resourcemanagerpb"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/api/option"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/proto"
+)
+
+// fakeIAMTagKeysServer serves the tag key "team" with an IAM policy granting the tag user role to an existing member.
+// With abort set, the first policy change fails as if the policy was changed concurrently.
+type fakeIAMTagKeysServer struct {
+	resourcemanagerpb.UnimplementedTagKeysServer
+	description string
+	abort       bool
+	mu          sync.Mutex
+	policy      *iampb.Policy
+	updates     int
+}
+
+// fakeIAMTagValuesServer serves the value "payments" of the tag key "team".
+type fakeIAMTagValuesServer struct {
+	resourcemanagerpb.UnimplementedTagValuesServer
+}
+
+func (s *fakeIAMTagKeysServer) GetNamespacedTagKey(ctx context.Context, req *resourcemanagerpb.GetNamespacedTagKeyRequest) (*resourcemanagerpb.TagKey, error) {
+	return &resourcemanagerpb.TagKey{Name: "tagKeys/1", ShortName: "team", NamespacedName: "test-project/team", Description: s.description}, nil
+}
+
+func (s *fakeIAMTagKeysServer) GetIamPolicy(ctx context.Context, req *iampb.GetIamPolicyRequest) (*iampb.Policy, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return proto.Clone(s.policy).(*iampb.Policy), nil
+}
+
+func (s *fakeIAMTagKeysServer) SetIamPolicy(ctx context.Context, req *iampb.SetIamPolicyRequest) (*iampb.Policy, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.updates++
+	if s.abort {
+		s.abort = false
+		return nil, status.Error(codes.Aborted, "concurrent policy changes")
+	}
+	s.policy = req.Policy
+	return s.policy, nil
+}
+
+func (s *fakeIAMTagKeysServer) members() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, binding := range s.policy.Bindings {
+		if binding.Role == TagUserRole {
+			return binding.Members
+		}
+	}
+	return nil
+}
+
+func (s *fakeIAMTagValuesServer) GetNamespacedTagValue(ctx context.Context, req *resourcemanagerpb.GetNamespacedTagValueRequest) (*resourcemanagerpb.TagValue, error) {
+	return &resourcemanagerpb.TagValue{Name: "tagValues/11", Parent: "tagKeys/1", ShortName: "payments", NamespacedName: req.Name}, nil
+}
+
+func newIAMTagsManager(t *testing.T, server *fakeIAMTagKeysServer, opts ...Option) TagsManager {
+	lis := bufconn.Listen(bufSize)
+	s := grpc.NewServer()
+	resourcemanagerpb.RegisterTagKeysServer(s, server)
+	resourcemanagerpb.RegisterTagValuesServer(s, &fakeIAMTagValuesServer{})
+	go func() {
+		if err := s.Serve(lis); err != nil && err != grpc.ErrServerStopped {
+			t.Errorf("Server exited with error: %v", err)
+		}
+	}()
+	t.Cleanup(s.Stop)
+
+	conn, err := grpc.DialContext(context.Background(), "bufnet", grpc.WithContextDialer(func(ctx context.Context, s string) (net.Conn, error) {
+		return bufDialer(lis)
+	}), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	assert.NoError(t, err, "Failed to dial bufnet")
+	t.Cleanup(func() { conn.Close() })
+
+	keysClient, err := resourcemanager.NewTagKeysClient(context.Background(), option.WithGRPCConn(conn))
+	assert.NoError(t, err, "Failed to create TagKeysClient")
+	valuesClient, err := resourcemanager.NewTagValuesClient(context.Background(), option.WithGRPCConn(conn))
+	assert.NoError(t, err, "Failed to create TagValuesClient")
+	return NewTagsManager(keysClient, valuesClient, nil, opts...)
+}
+
+func newIAMTagKeysServer(description string) *fakeIAMTagKeysServer {
+	return &fakeIAMTagKeysServer{
+		description: description,
+		policy: &iampb.Policy{
+			Etag:     []byte("etag"),
+			Bindings: []*iampb.Binding{{Role: TagUserRole, Members: []string{"group:admins@example.com"}}},
+		},
+	}
+}
+
+func TestEnsureValueGrantsTagUser(t *testing.T) {
+	server := newIAMTagKeysServer(OwnershipMarker)
+	mgr := newIAMTagsManager(t, server, WithTagUser("serviceAccount:cnrm@test-project.iam.gserviceaccount.com"))
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		_, err := mgr.EnsureValue(ctx, "test-project", "team", "payments")
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, 1, server.updates, "the grant should be remembered")
+
+	// the principal of the namespace takes precedence
+	ctx = ContextWithTagUser(ctx, "serviceAccount:cnrm-team-a@test-project.iam.gserviceaccount.com")
+	_, err := mgr.EnsureValue(ctx, "test-project", "team", "payments")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"group:admins@example.com",
+		"serviceAccount:cnrm@test-project.iam.gserviceaccount.com",
+		"serviceAccount:cnrm-team-a@test-project.iam.gserviceaccount.com",
+	}, server.members())
+}
+
+func TestGrantTagUserRetriesConcurrentChanges(t *testing.T) {
+	server := newIAMTagKeysServer(OwnershipMarker)
+	server.abort = true
+	mgr := newIAMTagsManager(t, server, WithTagUser("serviceAccount:cnrm@test-project.iam.gserviceaccount.com"))
+
+	_, err := mgr.EnsureValue(context.Background(), "test-project", "team", "payments")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, server.updates)
+	assert.Contains(t, server.members(), "serviceAccount:cnrm@test-project.iam.gserviceaccount.com")
+}
+
+func TestGrantTagUserSkipsForeignKeys(t *testing.T) {
+	server := newIAMTagKeysServer("created by hand")
+	mgr := newIAMTagsManager(t, server, WithTagUser("serviceAccount:cnrm@test-project.iam.gserviceaccount.com"))
+
+	_, err := mgr.EnsureValue(context.Background(), "test-project", "team", "payments")
+	assert.NoError(t, err)
+	assert.Zero(t, server.updates)
+}
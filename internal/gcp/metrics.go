@@ -0,0 +1,49 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gcp
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+var (
+	coalescedCallsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "tagging_operator_coalesced_calls_total",
+		Help: "Number of tag key and value lookups and creations served by an identical call already in flight.",
+	}, []string{"operation"})
+
+	tagAlreadyExistsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "tagging_operator_tag_already_exists_total",
+		Help: "Number of tag key and value creations which found the tag created concurrently.",
+	}, []string{"kind"})
+
+	retriesTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "tagging_operator_gcp_retries_total",
+		Help: "Number of Resource Manager calls retried after a transient error.",
+	}, []string{"class", "code"})
+
+	rateLimiterWaitSeconds = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "tagging_operator_rate_limiter_wait_seconds",
+		Help:    "Time Resource Manager calls waited for the client-side rate limit.",
+		Buckets: []float64{0.001, 0.01, 0.1, 0.5, 1, 2.5, 5, 10, 30},
+	}, []string{"class"})
+)
+
+func init() {
+	metrics.Registry.MustRegister(coalescedCallsTotal, tagAlreadyExistsTotal, retriesTotal, rateLimiterWaitSeconds)
+}
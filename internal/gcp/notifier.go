@@ -0,0 +1,48 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gcp
+
+import (
+	"context"
+
+	"cloud.google.com/go/resourcemanager/apiv3/resourcemanagerpb"
+)
+
+// CreationNotifier is informed about tag keys and values the TagsManager creates while serving a request.
+type CreationNotifier interface {
+	TagKeyCreated(key *resourcemanagerpb.TagKey)
+	TagValueCreated(value *resourcemanagerpb.TagValue)
+}
+
+type creationNotifierKey struct{}
+
+// ContextWithCreationNotifier returns a context which notifies n about created tag keys and values.
+func ContextWithCreationNotifier(ctx context.Context, n CreationNotifier) context.Context {
+	return context.WithValue(ctx, creationNotifierKey{}, n)
+}
+
+func notifyTagKeyCreated(ctx context.Context, key *resourcemanagerpb.TagKey) {
+	if n, ok := ctx.Value(creationNotifierKey{}).(CreationNotifier); ok {
+		n.TagKeyCreated(key)
+	}
+}
+
+func notifyTagValueCreated(ctx context.Context, value *resourcemanagerpb.TagValue) {
+	if n, ok := ctx.Value(creationNotifierKey{}).(CreationNotifier); ok {
+		n.TagValueCreated(value)
+	}
+}
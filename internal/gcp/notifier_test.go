@@ -0,0 +1,107 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gcp
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/longrunning/autogen/longrunningpb"
+	resourcemanager "cloud.google.com/go/resourcemanager/apiv3"
+	"cloud.google.com/go/resourcemanager/apiv3/resourcemanagerpb"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/api/option"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+type fakeCreatingTagKeysServer struct {
+	fakeTagKeysServer
+}
+
+func (s *fakeCreatingTagKeysServer) CreateTagKey(ctx context.Context, req *resourcemanagerpb.CreateTagKeyRequest) (*longrunningpb.Operation, error) {
+	key := &resourcemanagerpb.TagKey{
+		Name:           "tagKeys/123",
+		Parent:         req.TagKey.Parent,
+		ShortName:      req.TagKey.ShortName,
+		NamespacedName: tagNamespace(req.TagKey.Parent) + "/" + req.TagKey.ShortName,
+	}
+	response, err := anypb.New(key)
+	if err != nil {
+		return nil, err
+	}
+	return &longrunningpb.Operation{
+		Name:   "operations/create-key",
+		Done:   true,
+		Result: &longrunningpb.Operation_Response{Response: response},
+	}, nil
+}
+
+type recordingNotifier struct {
+	keys   []*resourcemanagerpb.TagKey
+	values []*resourcemanagerpb.TagValue
+}
+
+func (n *recordingNotifier) TagKeyCreated(key *resourcemanagerpb.TagKey) {
+	n.keys = append(n.keys, key)
+}
+
+func (n *recordingNotifier) TagValueCreated(value *resourcemanagerpb.TagValue) {
+	n.values = append(n.values, value)
+}
+
+func TestCreateKeyNotifiesCreation(t *testing.T) {
+	lis := bufconn.Listen(bufSize)
+
+	s := grpc.NewServer()
+	resourcemanagerpb.RegisterTagKeysServer(s, &fakeCreatingTagKeysServer{})
+
+	go func() {
+		if err := s.Serve(lis); err != nil && err != grpc.ErrServerStopped {
+			t.Errorf("Server exited with error: %v", err)
+		}
+	}()
+	defer s.Stop()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	conn, err := grpc.DialContext(ctx, "bufnet", grpc.WithContextDialer(func(ctx context.Context, s string) (net.Conn, error) {
+		return bufDialer(lis)
+	}), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	assert.NoError(t, err, "Failed to dial bufnet")
+	defer conn.Close()
+
+	keysClient, err := resourcemanager.NewTagKeysClient(ctx, option.WithGRPCConn(conn))
+	assert.NoError(t, err, "Failed to create TagKeysClient")
+
+	mgr := NewTagsManager(keysClient, nil, nil)
+
+	// without a notifier nothing should break
+	_, err = mgr.CreateKey(ctx, "test-project", "new-key")
+	assert.NoError(t, err, "CreateKey failed")
+
+	notifier := &recordingNotifier{}
+	key, err := mgr.CreateKey(ContextWithCreationNotifier(ctx, notifier), "test-project", "new-key")
+	assert.NoError(t, err, "CreateKey failed")
+	assert.Equal(t, []*resourcemanagerpb.TagKey{key}, notifier.keys)
+	assert.Empty(t, notifier.values)
+}
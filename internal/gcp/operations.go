@@ -0,0 +1,127 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"cloud.google.com/go/resourcemanager/apiv3/resourcemanagerpb"
+)
+
+const (
+	KindTagKey   = "TagKey"
+	KindTagValue = "TagValue"
+)
+
+// OperationPendingError is returned instead of waiting for a long-running operation creating a tag key or value.
+// Callers should retry later, or poll the operation with CheckOperation.
+type OperationPendingError struct {
+	// Kind is KindTagKey or KindTagValue.
+	Kind string
+	// Name is the namespaced name of the tag key or value being created.
+	Name string
+	// Operation is the name of the long-running operation.
+	Operation string
+}
+
+func (e *OperationPendingError) Error() string {
+	return fmt.Sprintf("creation of %s %s is still in progress (operation %s)", e.Kind, e.Name, e.Operation)
+}
+
+// AsOperationPending returns the OperationPendingError wrapped by err, if any.
+func AsOperationPending(err error) (*OperationPendingError, bool) {
+	var pending *OperationPendingError
+	ok := errors.As(err, &pending)
+	return pending, ok
+}
+
+// startOperation remembers a pending creation, so further lookups of the tag poll it instead of creating the tag again.
+func (m *tagsManager) startOperation(cacheKey string, pending *OperationPendingError) error {
+	m.pendingOperations.Store(cacheKey, pending)
+	// a cached lookup would still report the tag as missing once the operation is done
+	m.cache.Delete(cacheKey)
+	return pending
+}
+
+// awaitOperation checks the pending creation of a tag, returning the OperationPendingError while it is still running.
+func (m *tagsManager) awaitOperation(ctx context.Context, cacheKey string) error {
+	value, found := m.pendingOperations.Load(cacheKey)
+	if !found {
+		return nil
+	}
+	pending := value.(*OperationPendingError)
+	done, err := m.CheckOperation(ctx, pending.Kind, pending.Operation)
+	if err != nil {
+		return err
+	}
+	if !done {
+		return pending
+	}
+	return nil
+}
+
+// CheckOperation polls a long-running operation creating a tag key or value and reports whether it is done.
+// An operation which failed is done and returns its error.
+func (m *tagsManager) CheckOperation(ctx context.Context, kind string, name string) (bool, error) {
+	var done bool
+	var err error
+	switch kind {
+	case KindTagKey:
+		op := m.keysClient.CreateTagKeyOperation(name)
+		var tagKey *resourcemanagerpb.TagKey
+		tagKey, err = op.Poll(ctx)
+		done = op.Done()
+		if tagKey != nil {
+			notifyTagKeyCreated(ctx, tagKey)
+		}
+	case KindTagValue:
+		op := m.valuesClient.CreateTagValueOperation(name)
+		var tagValue *resourcemanagerpb.TagValue
+		tagValue, err = op.Poll(ctx)
+		done = op.Done()
+		if tagValue != nil {
+			notifyTagValueCreated(ctx, tagValue)
+		}
+	default:
+		return false, fmt.Errorf("unknown operation kind %q", kind)
+	}
+	if !done {
+		if err != nil {
+			return false, fmt.Errorf("failed to poll operation %s: %w", name, err)
+		}
+		return false, nil
+	}
+
+	m.forgetOperation(name)
+	if err != nil {
+		return true, fmt.Errorf("operation %s creating a %s failed: %w", name, kind, err)
+	}
+	return true, nil
+}
+
+// forgetOperation drops a finished operation and any lookup cached while it was running.
+func (m *tagsManager) forgetOperation(name string) {
+	m.pendingOperations.Range(func(key, value any) bool {
+		if value.(*OperationPendingError).Operation == name {
+			m.pendingOperations.Delete(key)
+			m.cache.Delete(key.(string))
+		}
+		return true
+	})
+}
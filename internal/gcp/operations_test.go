@@ -0,0 +1,152 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gcp
+
+import (
+	"context"
+	"net"
+	"sync/atomic"
+	"testing"
+
+	"cloud.google.com/go/longrunning/autogen/longrunningpb"
+	resourcemanager "cloud.google.com/go/resourcemanager/apiv3"
+	"cloud.google.com/go/resourcemanager/apiv3/resourcemanagerpb"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/api/option"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+var slowTagValue = &resourcemanagerpb.TagValue{
+	Name:           "tagValues/456",
+	Parent:         "tagKeys/test-project",
+	ShortName:      "staging",
+	NamespacedName: "test-project/env/staging",
+}
+
+// fakeSlowTagValuesServer creates tag values with operations which only complete once finished is set.
+type fakeSlowTagValuesServer struct {
+	resourcemanagerpb.UnimplementedTagValuesServer
+	longrunningpb.UnimplementedOperationsServer
+	finished  atomic.Bool
+	creations atomic.Int32
+}
+
+func (s *fakeSlowTagValuesServer) GetNamespacedTagValue(ctx context.Context, req *resourcemanagerpb.GetNamespacedTagValueRequest) (*resourcemanagerpb.TagValue, error) {
+	if s.finished.Load() {
+		return slowTagValue, nil
+	}
+	return nil, status.Error(codes.PermissionDenied, "permission denied or tag value does not exist")
+}
+
+func (s *fakeSlowTagValuesServer) ListTagValues(ctx context.Context, req *resourcemanagerpb.ListTagValuesRequest) (*resourcemanagerpb.ListTagValuesResponse, error) {
+	if s.finished.Load() {
+		return &resourcemanagerpb.ListTagValuesResponse{TagValues: []*resourcemanagerpb.TagValue{slowTagValue}}, nil
+	}
+	return &resourcemanagerpb.ListTagValuesResponse{}, nil
+}
+
+func (s *fakeSlowTagValuesServer) CreateTagValue(ctx context.Context, req *resourcemanagerpb.CreateTagValueRequest) (*longrunningpb.Operation, error) {
+	s.creations.Add(1)
+	return &longrunningpb.Operation{Name: "operations/create-value"}, nil
+}
+
+func (s *fakeSlowTagValuesServer) GetOperation(ctx context.Context, req *longrunningpb.GetOperationRequest) (*longrunningpb.Operation, error) {
+	if !s.finished.Load() {
+		return &longrunningpb.Operation{Name: req.Name}, nil
+	}
+	response, err := anypb.New(slowTagValue)
+	if err != nil {
+		return nil, err
+	}
+	return &longrunningpb.Operation{
+		Name:   req.Name,
+		Done:   true,
+		Result: &longrunningpb.Operation_Response{Response: response},
+	}, nil
+}
+
+func newSlowTagsManager(t *testing.T, server *fakeSlowTagValuesServer) TagsManager {
+	lis := bufconn.Listen(bufSize)
+	s := grpc.NewServer()
+	resourcemanagerpb.RegisterTagKeysServer(s, &fakeEnvTagKeysServer{})
+	resourcemanagerpb.RegisterTagValuesServer(s, server)
+	longrunningpb.RegisterOperationsServer(s, server)
+	go func() {
+		if err := s.Serve(lis); err != nil && err != grpc.ErrServerStopped {
+			t.Errorf("Server exited with error: %v", err)
+		}
+	}()
+	t.Cleanup(s.Stop)
+
+	conn, err := grpc.DialContext(context.Background(), "bufnet", grpc.WithContextDialer(func(ctx context.Context, s string) (net.Conn, error) {
+		return bufDialer(lis)
+	}), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	assert.NoError(t, err, "Failed to dial bufnet")
+	t.Cleanup(func() { conn.Close() })
+
+	keysClient, err := resourcemanager.NewTagKeysClient(context.Background(), option.WithGRPCConn(conn))
+	assert.NoError(t, err, "Failed to create TagKeysClient")
+	valuesClient, err := resourcemanager.NewTagValuesClient(context.Background(), option.WithGRPCConn(conn))
+	assert.NoError(t, err, "Failed to create TagValuesClient")
+	return NewTagsManager(keysClient, valuesClient, nil)
+}
+
+func TestEnsureValueDoesNotWaitForCreation(t *testing.T) {
+	server := &fakeSlowTagValuesServer{}
+	mgr := newSlowTagsManager(t, server)
+	ctx := context.Background()
+
+	_, err := mgr.EnsureValue(ctx, "test-project", "env", "staging")
+	pending, ok := AsOperationPending(err)
+	assert.True(t, ok, "expected a pending operation, got %v", err)
+	assert.Equal(t, &OperationPendingError{Kind: KindTagValue, Name: "test-project/env/staging", Operation: "operations/create-value"}, pending)
+
+	// the pending operation is polled instead of creating the value again
+	_, err = mgr.EnsureValue(ctx, "test-project", "env", "staging")
+	_, ok = AsOperationPending(err)
+	assert.True(t, ok, "expected a pending operation, got %v", err)
+	assert.Equal(t, int32(1), server.creations.Load())
+
+	server.finished.Store(true)
+	value, err := mgr.EnsureValue(ctx, "test-project", "env", "staging")
+	assert.NoError(t, err)
+	assert.Equal(t, "tagValues/456", value.Name)
+	assert.Equal(t, int32(1), server.creations.Load())
+}
+
+func TestCheckOperation(t *testing.T) {
+	server := &fakeSlowTagValuesServer{}
+	mgr := newSlowTagsManager(t, server)
+	ctx := context.Background()
+
+	done, err := mgr.CheckOperation(ctx, KindTagValue, "operations/create-value")
+	assert.NoError(t, err)
+	assert.False(t, done)
+
+	server.finished.Store(true)
+	done, err = mgr.CheckOperation(ctx, KindTagValue, "operations/create-value")
+	assert.NoError(t, err)
+	assert.True(t, done)
+
+	_, err = mgr.CheckOperation(ctx, "Project", "operations/create-value")
+	assert.Error(t, err)
+}
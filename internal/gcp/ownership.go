@@ -0,0 +1,139 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gcp
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"cloud.google.com/go/resourcemanager/apiv3/resourcemanagerpb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// OwnershipMarker starts the description of every tag key and value created by the operator.
+// Only tags carrying it are ever deleted.
+const OwnershipMarker = "Managed by gcp-config-connector-tagging-operator"
+
+// maxDescriptionLength is the maximum length of the description of a tag key or value.
+const maxDescriptionLength = 256
+
+// IsOwned reports whether a tag key or value with the given description was created or adopted by the operator.
+func IsOwned(description string) bool {
+	return strings.HasPrefix(description, OwnershipMarker)
+}
+
+type namespaceKey struct{}
+
+// ContextWithNamespace returns a context recording the namespace of the resource a request is served for,
+// which is noted in the description of created tag keys and values.
+func ContextWithNamespace(ctx context.Context, namespace string) context.Context {
+	return context.WithValue(ctx, namespaceKey{}, namespace)
+}
+
+// description returns the ownership marker, along with the cluster and namespace the tag is created for.
+func (m *tagsManager) description(ctx context.Context) string {
+	var details []string
+	if m.clusterName != "" {
+		details = append(details, "cluster "+m.clusterName)
+	}
+	if namespace, ok := ctx.Value(namespaceKey{}).(string); ok && namespace != "" {
+		details = append(details, "namespace "+namespace)
+	}
+
+	description := OwnershipMarker
+	if len(details) > 0 {
+		description = fmt.Sprintf("%s (%s)", description, strings.Join(details, ", "))
+	}
+	if runes := []rune(description); len(runes) > maxDescriptionLength {
+		description = string(runes[:maxDescriptionLength])
+	}
+	return description
+}
+
+// ownsKey reports whether the tag key with the given resource name carries the ownership marker.
+func (m *tagsManager) ownsKey(ctx context.Context, name string) (bool, error) {
+	tagKey, err := m.keysClient.GetTagKey(ctx, &resourcemanagerpb.GetTagKeyRequest{Name: name})
+	if err != nil {
+		return false, err
+	}
+	return IsOwned(tagKey.Description), nil
+}
+
+// ownsValue reports whether the tag value with the given resource name carries the ownership marker.
+func (m *tagsManager) ownsValue(ctx context.Context, name string) (bool, error) {
+	tagValue, err := m.valuesClient.GetTagValue(ctx, &resourcemanagerpb.GetTagValueRequest{Name: name})
+	if err != nil {
+		return false, err
+	}
+	return IsOwned(tagValue.Description), nil
+}
+
+// deletable checks the ownership of a tag key or value before deleting it. Tags which are gone already are
+// invalidated and reported as not deletable, just like tags created by someone else.
+func (m *tagsManager) deletable(ctx context.Context, kind string, name string) (bool, error) {
+	owns := m.ownsKey
+	if kind == KindTagValue {
+		owns = m.ownsValue
+	}
+
+	owned, err := owns(ctx, name)
+	if status.Code(err) == codes.NotFound {
+		m.Invalidate(name)
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to check the ownership of %s %s: %w", kind, name, err)
+	}
+	if !owned {
+		log.FromContext(ctx).Info("not deleting tag created outside of the operator", "kind", kind, "name", name)
+	}
+	return owned, nil
+}
+
+// adoptKey stamps the ownership marker on an existing tag key, making it subject to deletion once unused.
+// It waits for the update, so only the adopted key is cached. Failures are only logged, as the key can be used
+// nevertheless.
+func (m *tagsManager) adoptKey(ctx context.Context, cacheKey string, tagKey *resourcemanagerpb.TagKey) *resourcemanagerpb.TagKey {
+	adopted := proto.Clone(tagKey).(*resourcemanagerpb.TagKey)
+	adopted.Description = m.description(ctx)
+	adopted, err := m.writer.updateKey(ctx, adopted)
+	if err != nil {
+		log.FromContext(ctx).Error(err, "failed to adopt tag key", "tagKey", tagKey.NamespacedName)
+		return tagKey
+	}
+	log.FromContext(ctx).Info("adopted tag key", "tagKey", tagKey.NamespacedName)
+	m.cacheSet(cacheKey, adopted.Name, adopted)
+	return adopted
+}
+
+// adoptValue stamps the ownership marker on an existing tag value, like adoptKey.
+func (m *tagsManager) adoptValue(ctx context.Context, cacheKey string, tagValue *resourcemanagerpb.TagValue) *resourcemanagerpb.TagValue {
+	adopted := proto.Clone(tagValue).(*resourcemanagerpb.TagValue)
+	adopted.Description = m.description(ctx)
+	adopted, err := m.writer.updateValue(ctx, adopted)
+	if err != nil {
+		log.FromContext(ctx).Error(err, "failed to adopt tag value", "tagValue", tagValue.NamespacedName)
+		return tagValue
+	}
+	log.FromContext(ctx).Info("adopted tag value", "tagValue", tagValue.NamespacedName)
+	m.cacheSet(cacheKey, adopted.Name, adopted)
+	return adopted
+}
@@ -0,0 +1,230 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gcp
+
+import (
+	"context"
+	"net"
+	"sync"
+	"testing"
+
+	"cloud.google.com/go/longrunning/autogen/longrunningpb"
+	resourcemanager "cloud.google.com/go/resourcemanager/apiv3"
+	"cloud.google.com/go/resourcemanager/apiv3/resourcemanagerpb"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/api/option"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+// fakeOwnershipServer serves the tag key "team" with the values "owned", "foreign" and "bound". "foreign" was created
+// by hand, "bound" is still in use and cannot be deleted.
+// It records the descriptions of created and updated tags, and the names of deleted ones. With failUpdates, updates
+// of tag keys are accepted, but their operations fail.
+type fakeOwnershipServer struct {
+	mu           sync.Mutex
+	descriptions []string
+	updates      int
+	failUpdates  bool
+	deleted      []string
+}
+
+type fakeOwnershipTagKeysServer struct {
+	resourcemanagerpb.UnimplementedTagKeysServer
+	*fakeOwnershipServer
+}
+
+type fakeOwnershipTagValuesServer struct {
+	resourcemanagerpb.UnimplementedTagValuesServer
+	*fakeOwnershipServer
+}
+
+var ownershipTagKey = &resourcemanagerpb.TagKey{
+	Name:           "tagKeys/1",
+	ShortName:      "team",
+	NamespacedName: "test-project/team",
+}
+
+var ownershipTagValues = map[string]*resourcemanagerpb.TagValue{
+	"tagValues/11": {Name: "tagValues/11", Parent: "tagKeys/1", ShortName: "owned", NamespacedName: "test-project/team/owned", Description: OwnershipMarker},
+	"tagValues/12": {Name: "tagValues/12", Parent: "tagKeys/1", ShortName: "foreign", NamespacedName: "test-project/team/foreign"},
+	"tagValues/14": {Name: "tagValues/14", Parent: "tagKeys/1", ShortName: "bound", NamespacedName: "test-project/team/bound", Description: OwnershipMarker},
+}
+
+func doneOperation(response proto.Message) (*longrunningpb.Operation, error) {
+	result, err := anypb.New(response)
+	if err != nil {
+		return nil, err
+	}
+	return &longrunningpb.Operation{Name: "operations/1", Done: true, Result: &longrunningpb.Operation_Response{Response: result}}, nil
+}
+
+func (s *fakeOwnershipTagKeysServer) GetNamespacedTagKey(ctx context.Context, req *resourcemanagerpb.GetNamespacedTagKeyRequest) (*resourcemanagerpb.TagKey, error) {
+	return ownershipTagKey, nil
+}
+
+func (s *fakeOwnershipTagKeysServer) GetTagKey(ctx context.Context, req *resourcemanagerpb.GetTagKeyRequest) (*resourcemanagerpb.TagKey, error) {
+	return ownershipTagKey, nil
+}
+
+func (s *fakeOwnershipTagKeysServer) UpdateTagKey(ctx context.Context, req *resourcemanagerpb.UpdateTagKeyRequest) (*longrunningpb.Operation, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.updates++
+	s.descriptions = append(s.descriptions, req.TagKey.Description)
+	if s.failUpdates {
+		return &longrunningpb.Operation{
+			Name:   "operations/1",
+			Done:   true,
+			Result: &longrunningpb.Operation_Error{Error: status.New(codes.PermissionDenied, "permission denied").Proto()},
+		}, nil
+	}
+	return doneOperation(req.TagKey)
+}
+
+func (s *fakeOwnershipTagValuesServer) GetTagValue(ctx context.Context, req *resourcemanagerpb.GetTagValueRequest) (*resourcemanagerpb.TagValue, error) {
+	return ownershipTagValues[req.Name], nil
+}
+
+func (s *fakeOwnershipTagValuesServer) GetNamespacedTagValue(ctx context.Context, req *resourcemanagerpb.GetNamespacedTagValueRequest) (*resourcemanagerpb.TagValue, error) {
+	return nil, status.Error(codes.PermissionDenied, "permission denied or tag value does not exist")
+}
+
+func (s *fakeOwnershipTagValuesServer) ListTagValues(ctx context.Context, req *resourcemanagerpb.ListTagValuesRequest) (*resourcemanagerpb.ListTagValuesResponse, error) {
+	return &resourcemanagerpb.ListTagValuesResponse{}, nil
+}
+
+func (s *fakeOwnershipTagValuesServer) CreateTagValue(ctx context.Context, req *resourcemanagerpb.CreateTagValueRequest) (*longrunningpb.Operation, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.descriptions = append(s.descriptions, req.TagValue.Description)
+	created := proto.Clone(req.TagValue).(*resourcemanagerpb.TagValue)
+	created.Name = "tagValues/13"
+	return doneOperation(created)
+}
+
+func (s *fakeOwnershipTagValuesServer) DeleteTagValue(ctx context.Context, req *resourcemanagerpb.DeleteTagValueRequest) (*longrunningpb.Operation, error) {
+	if req.Name == "tagValues/14" {
+		return nil, status.Error(codes.FailedPrecondition, "tag value is bound to resources")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.deleted = append(s.deleted, req.Name)
+	return doneOperation(ownershipTagValues[req.Name])
+}
+
+func newOwnershipTagsManager(t *testing.T, server *fakeOwnershipServer, opts ...Option) TagsManager {
+	lis := bufconn.Listen(bufSize)
+	s := grpc.NewServer()
+	resourcemanagerpb.RegisterTagKeysServer(s, &fakeOwnershipTagKeysServer{fakeOwnershipServer: server})
+	resourcemanagerpb.RegisterTagValuesServer(s, &fakeOwnershipTagValuesServer{fakeOwnershipServer: server})
+	go func() {
+		if err := s.Serve(lis); err != nil && err != grpc.ErrServerStopped {
+			t.Errorf("Server exited with error: %v", err)
+		}
+	}()
+	t.Cleanup(s.Stop)
+
+	conn, err := grpc.DialContext(context.Background(), "bufnet", grpc.WithContextDialer(func(ctx context.Context, s string) (net.Conn, error) {
+		return bufDialer(lis)
+	}), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	assert.NoError(t, err, "Failed to dial bufnet")
+	t.Cleanup(func() { conn.Close() })
+
+	keysClient, err := resourcemanager.NewTagKeysClient(context.Background(), option.WithGRPCConn(conn))
+	assert.NoError(t, err, "Failed to create TagKeysClient")
+	valuesClient, err := resourcemanager.NewTagValuesClient(context.Background(), option.WithGRPCConn(conn))
+	assert.NoError(t, err, "Failed to create TagValuesClient")
+	return NewTagsManager(keysClient, valuesClient, nil, opts...)
+}
+
+func TestCreateValueStampsOwnership(t *testing.T) {
+	server := &fakeOwnershipServer{}
+	mgr := newOwnershipTagsManager(t, server, WithClusterName("prod"))
+
+	ctx := ContextWithNamespace(context.Background(), "team-a")
+	value, err := mgr.EnsureValue(ctx, "test-project", "team", "new")
+	assert.NoError(t, err)
+	assert.Equal(t, "tagValues/13", value.Name)
+	assert.Equal(t, []string{OwnershipMarker + " (cluster prod, namespace team-a)"}, server.descriptions)
+	assert.True(t, IsOwned(value.Description))
+}
+
+func TestDeleteSkipsForeignTags(t *testing.T) {
+	server := &fakeOwnershipServer{}
+	mgr := newOwnershipTagsManager(t, server)
+	ctx := context.Background()
+
+	deleted, err := mgr.DeleteValueIfUnused(ctx, "test-project", "tagKeys/1", "tagValues/11")
+	assert.NoError(t, err)
+	assert.True(t, deleted)
+	deleted, err = mgr.DeleteValueIfUnused(ctx, "test-project", "tagKeys/1", "tagValues/12")
+	assert.NoError(t, err)
+	assert.False(t, deleted)
+	// the key was created by hand as well
+	assert.NoError(t, mgr.DeleteKeyIfUnused(ctx, "test-project", "tagKeys/1"))
+	assert.Equal(t, []string{"tagValues/11"}, server.deleted)
+}
+
+func TestDeleteKeepsValuesInUse(t *testing.T) {
+	server := &fakeOwnershipServer{}
+	mgr := newOwnershipTagsManager(t, server)
+
+	deleted, err := mgr.DeleteValueIfUnused(context.Background(), "test-project", "tagKeys/1", "tagValues/14")
+	assert.NoError(t, err)
+	assert.False(t, deleted)
+	assert.Empty(t, server.deleted)
+}
+
+func TestAdoptExistingKeys(t *testing.T) {
+	server := &fakeOwnershipServer{}
+	mgr := newOwnershipTagsManager(t, server, WithAdoptExisting(true))
+	ctx := context.Background()
+
+	key, err := mgr.EnsureKey(ctx, "test-project", "team")
+	assert.NoError(t, err)
+	assert.True(t, IsOwned(key.Description))
+	assert.False(t, IsOwned(ownershipTagKey.Description), "the shared tag key must not be modified")
+
+	_, err = mgr.EnsureKey(ctx, "test-project", "team")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, server.updates)
+}
+
+func TestFailedAdoptionIsNotCached(t *testing.T) {
+	server := &fakeOwnershipServer{failUpdates: true}
+	mgr := newOwnershipTagsManager(t, server, WithAdoptExisting(true))
+	ctx := context.Background()
+
+	key, err := mgr.EnsureKey(ctx, "test-project", "team")
+	assert.NoError(t, err, "the key can be used without being adopted")
+	assert.False(t, IsOwned(key.Description))
+
+	_, err = mgr.EnsureKey(ctx, "test-project", "team")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, server.updates, "the adoption should be retried")
+}
+
+func TestDescriptionIsTruncated(t *testing.T) {
+	m := &tagsManager{clusterName: string(make([]rune, 300))}
+	assert.Len(t, []rune(m.description(context.Background())), maxDescriptionLength)
+}
@@ -0,0 +1,222 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gcp
+
+import (
+	"context"
+	"math/rand"
+	"reflect"
+	"strings"
+	"time"
+
+	resourcemanager "cloud.google.com/go/resourcemanager/apiv3"
+	"github.com/googleapis/gax-go/v2"
+	"golang.org/x/time/rate"
+	"google.golang.org/api/option"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Resource Manager quotas differ for reads and writes, so each class of calls has its own limit.
+const (
+	RPCClassRead  = "read"
+	RPCClassWrite = "write"
+)
+
+// Defaults of RateLimitConfig, well below the default Resource Manager quotas.
+const (
+	DefaultReadQPS        = 20
+	DefaultReadBurst      = 40
+	DefaultWriteQPS       = 5
+	DefaultWriteBurst     = 10
+	DefaultMaxRetries     = 5
+	DefaultRetryBaseDelay = 500 * time.Millisecond
+	DefaultRetryMaxDelay  = 30 * time.Second
+)
+
+// RateLimitConfig configures the client-side rate limits and retries of Resource Manager calls.
+type RateLimitConfig struct {
+	// ReadQPS and ReadBurst limit lookups and listings, WriteQPS and WriteBurst all other calls. 0 QPS disables a limit.
+	ReadQPS    float64
+	ReadBurst  int
+	WriteQPS   float64
+	WriteBurst int
+	// MaxRetries is how often a call failing with a retryable error is retried. 0 disables retries.
+	MaxRetries int
+	// RetryBaseDelay and RetryMaxDelay bound the exponential backoff between retries,
+	// unless the server asks for a longer delay.
+	RetryBaseDelay time.Duration
+	RetryMaxDelay  time.Duration
+}
+
+// DefaultRateLimitConfig returns the default rate limits and retries.
+func DefaultRateLimitConfig() RateLimitConfig {
+	return RateLimitConfig{
+		ReadQPS:        DefaultReadQPS,
+		ReadBurst:      DefaultReadBurst,
+		WriteQPS:       DefaultWriteQPS,
+		WriteBurst:     DefaultWriteBurst,
+		MaxRetries:     DefaultMaxRetries,
+		RetryBaseDelay: DefaultRetryBaseDelay,
+		RetryMaxDelay:  DefaultRetryMaxDelay,
+	}
+}
+
+// RateLimiter limits and retries the calls of all Resource Manager clients sharing it.
+type RateLimiter struct {
+	limiters map[string]*rate.Limiter
+	config   RateLimitConfig
+}
+
+func NewRateLimiter(config RateLimitConfig) *RateLimiter {
+	return &RateLimiter{
+		limiters: map[string]*rate.Limiter{
+			RPCClassRead:  newLimiter(config.ReadQPS, config.ReadBurst),
+			RPCClassWrite: newLimiter(config.WriteQPS, config.WriteBurst),
+		},
+		config: config,
+	}
+}
+
+func newLimiter(qps float64, burst int) *rate.Limiter {
+	if qps <= 0 {
+		return rate.NewLimiter(rate.Inf, 0)
+	}
+	return rate.NewLimiter(rate.Limit(qps), max(burst, 1))
+}
+
+// ClientOption applies the rate limiter to a Resource Manager client.
+func (l *RateLimiter) ClientOption() option.ClientOption {
+	return option.WithGRPCDialOption(grpc.WithChainUnaryInterceptor(l.UnaryClientInterceptor()))
+}
+
+// UnaryClientInterceptor waits for the limit of the class of each call, and retries calls failing with retryable errors.
+// Writes are only retried if their quota is exhausted, see isRetryableCall.
+func (l *RateLimiter) UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		class := rpcClass(method)
+		limiter := l.limiters[class]
+		for attempt := 0; ; attempt++ {
+			start := time.Now()
+			if err := limiter.Wait(ctx); err != nil {
+				if ctx.Err() != nil {
+					return status.FromContextError(ctx.Err()).Err()
+				}
+				// the limit does not allow the call before the deadline of ctx
+				return status.Error(codes.DeadlineExceeded, err.Error())
+			}
+			rateLimiterWaitSeconds.WithLabelValues(class).Observe(time.Since(start).Seconds())
+
+			err := invoker(ctx, method, req, reply, cc, opts...)
+			if err == nil || attempt >= l.config.MaxRetries || !isRetryableCall(class, err) || ctx.Err() != nil {
+				return err
+			}
+			retriesTotal.WithLabelValues(class, status.Code(err).String()).Inc()
+
+			timer := time.NewTimer(retryDelay(err, attempt, l.config.RetryBaseDelay, l.config.RetryMaxDelay))
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return err
+			case <-timer.C:
+			}
+		}
+	}
+}
+
+// rpcClass tells reads from writes by the method name, e.g. /google.cloud.resourcemanager.v3.TagKeys/GetNamespacedTagKey.
+// Polling long-running operations counts as a read.
+func rpcClass(method string) string {
+	name := method[strings.LastIndex(method, "/")+1:]
+	for _, prefix := range []string{"Get", "List", "Search", "Test"} {
+		if strings.HasPrefix(name, prefix) {
+			return RPCClassRead
+		}
+	}
+	return RPCClassWrite
+}
+
+// IsRetryable reports whether a call failed with a transient error, like an exhausted quota or an unavailable
+// service. All other errors are permanent and retrying the call does not change the outcome.
+func IsRetryable(err error) bool {
+	switch status.Code(err) {
+	case codes.ResourceExhausted, codes.Unavailable, codes.Aborted, codes.DeadlineExceeded:
+		return true
+	default:
+		return false
+	}
+}
+
+// isRetryableCall reports whether a failed call can be retried. Writes are not idempotent, e.g. a retried creation
+// may create a tag twice, so they are only retried if they were rejected for an exhausted quota without being executed.
+func isRetryableCall(class string, err error) bool {
+	if class == RPCClassWrite {
+		return status.Code(err) == codes.ResourceExhausted
+	}
+	return IsRetryable(err)
+}
+
+// DisableClientRetries turns off the retries the Resource Manager clients configure for their methods, as calls
+// through a RateLimiter are retried by it already. callOptions are the CallOptions of a client, e.g.
+// TagKeysClient.CallOptions.
+func DisableClientRetries(callOptions any) {
+	noRetry := gax.WithRetry(nil)
+	v := reflect.ValueOf(callOptions).Elem()
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Field(i)
+		if opts, ok := field.Interface().([]gax.CallOption); ok && field.CanSet() {
+			field.Set(reflect.ValueOf(append(opts, noRetry)))
+		}
+	}
+}
+
+// TagBindingsClientFactory returns a factory like NewTagBindingsClientFactory, which creates clients calling through
+// the RateLimiter.
+func (l *RateLimiter) TagBindingsClientFactory() TagBindingsClientFactory {
+	factory := NewTagBindingsClientFactory(l.ClientOption())
+	return func(ctx context.Context, location string) (*resourcemanager.TagBindingsClient, error) {
+		client, err := factory(ctx, location)
+		if err != nil {
+			return nil, err
+		}
+		DisableClientRetries(client.CallOptions)
+		return client, nil
+	}
+}
+
+// retryDelay returns the delay the server asked for with RetryInfo, or an exponential backoff otherwise.
+// Both are jittered, so the calls of concurrent reconciliations do not retry in lockstep.
+func retryDelay(err error, attempt int, base, maxDelay time.Duration) time.Duration {
+	if s, ok := status.FromError(err); ok {
+		for _, detail := range s.Details() {
+			if info, ok := detail.(*errdetails.RetryInfo); ok && info.GetRetryDelay() != nil {
+				delay := info.GetRetryDelay().AsDuration()
+				// never earlier than requested, up to 20% later
+				return delay + time.Duration(rand.Int63n(int64(delay)/5+1))
+			}
+		}
+	}
+
+	backoff := maxDelay
+	if attempt < 32 && base<<attempt > 0 && base<<attempt < maxDelay {
+		backoff = base << attempt
+	}
+	// full jitter
+	return time.Duration(rand.Int63n(int64(backoff) + 1))
+}
@@ -0,0 +1,149 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gcp
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	resourcemanager "cloud.google.com/go/resourcemanager/apiv3"
+	"github.com/googleapis/gax-go/v2"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+func TestRPCClass(t *testing.T) {
+	for method, expected := range map[string]string{
+		"/google.cloud.resourcemanager.v3.TagKeys/GetNamespacedTagKey": RPCClassRead,
+		"/google.cloud.resourcemanager.v3.TagValues/ListTagValues":     RPCClassRead,
+		"/google.cloud.resourcemanager.v3.TagKeys/CreateTagKey":        RPCClassWrite,
+		"/google.cloud.resourcemanager.v3.TagValues/DeleteTagValue":    RPCClassWrite,
+		"/google.longrunning.Operations/GetOperation":                  RPCClassRead,
+	} {
+		assert.Equal(t, expected, rpcClass(method), method)
+	}
+}
+
+func TestIsRetryable(t *testing.T) {
+	assert.True(t, IsRetryable(status.Error(codes.ResourceExhausted, "quota exceeded")))
+	assert.True(t, IsRetryable(status.Error(codes.Unavailable, "unavailable")))
+	assert.False(t, IsRetryable(status.Error(codes.PermissionDenied, "permission denied")))
+	assert.False(t, IsRetryable(status.Error(codes.InvalidArgument, "invalid short name")))
+	assert.False(t, IsRetryable(errors.New("unknown")))
+}
+
+func TestDisableClientRetries(t *testing.T) {
+	options := &resourcemanager.TagKeysCallOptions{
+		GetTagKey: []gax.CallOption{gax.WithRetry(func() gax.Retryer {
+			return gax.OnCodes([]codes.Code{codes.Unavailable}, gax.Backoff{})
+		})},
+	}
+	DisableClientRetries(options)
+
+	var settings gax.CallSettings
+	for _, opt := range options.GetTagKey {
+		opt.Resolve(&settings)
+	}
+	assert.Nil(t, settings.Retry)
+	assert.Len(t, options.CreateTagKey, 1)
+}
+
+func TestRetryDelay(t *testing.T) {
+	for attempt := 0; attempt < 40; attempt++ {
+		delay := retryDelay(status.Error(codes.Unavailable, "unavailable"), attempt, 100*time.Millisecond, time.Second)
+		assert.LessOrEqual(t, delay, time.Second)
+		assert.GreaterOrEqual(t, delay, time.Duration(0))
+	}
+
+	s, err := status.New(codes.ResourceExhausted, "quota exceeded").WithDetails(&errdetails.RetryInfo{RetryDelay: durationpb.New(10 * time.Second)})
+	assert.NoError(t, err)
+	delay := retryDelay(s.Err(), 0, 100*time.Millisecond, time.Second)
+	assert.GreaterOrEqual(t, delay, 10*time.Second)
+	assert.LessOrEqual(t, delay, 12*time.Second)
+}
+
+// failingInvoker fails the first calls with err and counts all calls.
+func failingInvoker(failures int, err error, calls *int) grpc.UnaryInvoker {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		*calls++
+		if *calls <= failures {
+			return err
+		}
+		return nil
+	}
+}
+
+func TestRateLimiterRetries(t *testing.T) {
+	config := DefaultRateLimitConfig()
+	config.MaxRetries = 3
+	config.RetryBaseDelay = time.Millisecond
+	config.RetryMaxDelay = time.Millisecond
+	interceptor := NewRateLimiter(config).UnaryClientInterceptor()
+	method := "/google.cloud.resourcemanager.v3.TagKeys/CreateTagKey"
+	ctx := context.Background()
+
+	calls := 0
+	err := interceptor(ctx, method, nil, nil, nil, failingInvoker(2, status.Error(codes.ResourceExhausted, "quota exceeded"), &calls))
+	assert.NoError(t, err)
+	assert.Equal(t, 3, calls)
+
+	calls = 0
+	err = interceptor(ctx, method, nil, nil, nil, failingInvoker(10, status.Error(codes.Unavailable, "unavailable"), &calls))
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+	assert.Equal(t, 1, calls, "writes may have been executed and must not be retried")
+
+	calls = 0
+	read := "/google.cloud.resourcemanager.v3.TagKeys/GetNamespacedTagKey"
+	err = interceptor(ctx, read, nil, nil, nil, failingInvoker(10, status.Error(codes.Unavailable, "unavailable"), &calls))
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+	assert.Equal(t, 4, calls)
+
+	calls = 0
+	err = interceptor(ctx, method, nil, nil, nil, failingInvoker(10, status.Error(codes.PermissionDenied, "permission denied"), &calls))
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	assert.Equal(t, 1, calls)
+}
+
+func TestRateLimiterLimitsClasses(t *testing.T) {
+	config := DefaultRateLimitConfig()
+	config.ReadQPS = 0
+	config.WriteQPS = 1
+	config.WriteBurst = 1
+	interceptor := NewRateLimiter(config).UnaryClientInterceptor()
+	calls := 0
+	invoker := failingInvoker(0, nil, &calls)
+
+	// reads are not limited
+	for i := 0; i < 10; i++ {
+		assert.NoError(t, interceptor(context.Background(), "/google.cloud.resourcemanager.v3.TagKeys/GetTagKey", nil, nil, nil, invoker))
+	}
+
+	// the second write has to wait for a second, longer than the context allows
+	write := "/google.cloud.resourcemanager.v3.TagKeys/CreateTagKey"
+	assert.NoError(t, interceptor(context.Background(), write, nil, nil, nil, invoker))
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	err := interceptor(ctx, write, nil, nil, nil, invoker)
+	assert.Error(t, err)
+	assert.Equal(t, 11, calls)
+}
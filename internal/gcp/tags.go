@@ -20,140 +20,549 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
+	"sync"
 	"time"
 
 	resourcemanager "cloud.google.com/go/resourcemanager/apiv3"
 	"cloud.google.com/go/resourcemanager/apiv3/resourcemanagerpb"
 	"github.com/googleapis/gax-go/v2/apierror"
-	cache "github.com/patrickmn/go-cache"
+	"golang.org/x/sync/singleflight"
+	"google.golang.org/api/iterator"
+	"google.golang.org/api/option"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"sigs.k8s.io/controller-runtime/pkg/log"
 )
 
-const (
-	tagCacheDuration = 5 * time.Minute
-)
+// ErrNotFound is returned by GetKey and GetValue for tag keys and values which do not exist.
+var ErrNotFound = errors.New("not found")
+
+// IsNotFound reports whether err means that a tag key or value does not exist.
+func IsNotFound(err error) bool {
+	return errors.Is(err, ErrNotFound)
+}
 
 type TagsManager interface {
-	LookupKey(ctx context.Context, projectID string, key string) (*resourcemanagerpb.TagKey, error)
+	// GetKey looks up an existing tag key and never creates it.
+	GetKey(ctx context.Context, projectID string, key string) (*resourcemanagerpb.TagKey, error)
+	// EnsureKey looks up a tag key and creates it if it does not exist, unless creation is disabled.
+	EnsureKey(ctx context.Context, projectID string, key string) (*resourcemanagerpb.TagKey, error)
 	CreateKey(ctx context.Context, projectID string, key string) (*resourcemanagerpb.TagKey, error)
-	LookupValue(ctx context.Context, projectID string, key string, value string) (*resourcemanagerpb.TagValue, error)
+	// FirewallNetwork reports whether values of the tag key are secure tags, and the VPC network they apply to.
+	FirewallNetwork(ctx context.Context, projectID string, key string) (string, bool, error)
+	// GetValue looks up an existing tag value and never creates it.
+	GetValue(ctx context.Context, projectID string, key string, value string) (*resourcemanagerpb.TagValue, error)
+	// EnsureValue looks up a tag value and creates it and its key if they do not exist, unless creation is disabled.
+	EnsureValue(ctx context.Context, projectID string, key string, value string) (*resourcemanagerpb.TagValue, error)
 	CreateValue(ctx context.Context, projectID string, key string, value string) (*resourcemanagerpb.TagValue, error)
 	GetProjectInfo(ctx context.Context, projectID string) (*resourcemanagerpb.Project, error)
-	DeleteValueIfUnused(ctx context.Context, projectID string, key string, value string) error
+	DeleteValueIfUnused(ctx context.Context, projectID string, key string, value string) (bool, error)
 	DeleteKeyIfUnused(ctx context.Context, projectID string, key string) error
+	TagParents(projectIDs ...string) []string
+	ListKeys(ctx context.Context, parent string) ([]*resourcemanagerpb.TagKey, error)
+	ListValues(ctx context.Context, key string) ([]*resourcemanagerpb.TagValue, error)
+	ListBindings(ctx context.Context, location string, parent string) ([]*resourcemanagerpb.TagBinding, error)
+	// WarmCache loads the tag keys of parent and their values into the cache, see tagsManager.WarmCache.
+	WarmCache(ctx context.Context, parent string, include func(key string) bool) (int, error)
+	// CheckOperation polls an operation creating a tag key or value, see OperationPendingError.
+	CheckOperation(ctx context.Context, kind string, name string) (bool, error)
+	Invalidate(name string)
 }
 
+// TagBindingsClientFactory creates a TagBindings client for a resource location.
+type TagBindingsClientFactory func(ctx context.Context, location string) (*resourcemanager.TagBindingsClient, error)
+
 type tagsManager struct {
 	keysClient     *resourcemanager.TagKeysClient
 	valuesClient   *resourcemanager.TagValuesClient
 	projectsClient *resourcemanager.ProjectsClient
-	cache          *cache.Cache
+	writer         tagWriter
+	tagParent      string
+	keyParents     map[string]string
+	allowCreate    bool
+	clusterName    string
+	adoptExisting  bool
+	firewallKeys   map[string]string
+	tagUser        string
+	tagUserGrants  sync.Map
+
+	bindingsClientFactory TagBindingsClientFactory
+	bindingsClients       sync.Map
+
+	inflight          singleflight.Group
+	pendingOperations sync.Map
+
+	cache            Cache
+	cacheTTL         time.Duration
+	negativeCacheTTL time.Duration
 }
 
-// TODO add logging to this file
+// Option configures optional behaviour of the TagsManager.
+type Option func(*tagsManager)
 
-func NewTagsManager(keysClient *resourcemanager.TagKeysClient, valuesClient *resourcemanager.TagValuesClient, projectClient *resourcemanager.ProjectsClient) TagsManager {
-	return &tagsManager{
+// WithTagParent places all tag keys and values under a single parent, either
+// "organizations/<id>" or "projects/<id>", instead of the resource's own project.
+func WithTagParent(parent string) Option {
+	return func(m *tagsManager) {
+		m.tagParent = parent
+	}
+}
+
+// WithKeyParents overrides the tag parent for individual tag keys.
+func WithKeyParents(parents map[string]string) Option {
+	return func(m *tagsManager) {
+		m.keyParents = parents
+	}
+}
+
+// WithAllowCreate controls whether missing tag keys and values are created. Without, only existing ones can be used.
+func WithAllowCreate(allow bool) Option {
+	return func(m *tagsManager) {
+		m.allowCreate = allow
+	}
+}
+
+// WithClusterName notes the cluster in the description of created tag keys and values.
+func WithClusterName(name string) Option {
+	return func(m *tagsManager) {
+		m.clusterName = name
+	}
+}
+
+// WithAdoptExisting stamps the ownership marker on existing tag keys and values the operator uses,
+// so they are deleted once unused like the ones it created.
+func WithAdoptExisting(adopt bool) Option {
+	return func(m *tagsManager) {
+		m.adoptExisting = adopt
+	}
+}
+
+// WithCache replaces the default in-memory cache.
+func WithCache(cache Cache) Option {
+	return func(m *tagsManager) {
+		m.cache = cache
+	}
+}
+
+// WithCacheTTL sets how long tag keys, tag values and projects are cached.
+func WithCacheTTL(ttl time.Duration) Option {
+	return func(m *tagsManager) {
+		m.cacheTTL = ttl
+	}
+}
+
+// WithNegativeCacheTTL sets how long missing tag keys and values are cached. 0 disables negative caching.
+func WithNegativeCacheTTL(ttl time.Duration) Option {
+	return func(m *tagsManager) {
+		m.negativeCacheTTL = ttl
+	}
+}
+
+// WithTagBindingsClientFactory overrides how TagBindings clients are created for a location.
+func WithTagBindingsClientFactory(factory TagBindingsClientFactory) Option {
+	return func(m *tagsManager) {
+		m.bindingsClientFactory = factory
+	}
+}
+
+// NewTagBindingsClientForLocation creates a TagBindings client using the location specific
+// Resource Manager endpoint, which is required to read bindings of regional resources.
+func NewTagBindingsClientForLocation(ctx context.Context, location string) (*resourcemanager.TagBindingsClient, error) {
+	return NewTagBindingsClientFactory()(ctx, location)
+}
+
+// NewTagBindingsClientFactory returns a factory like NewTagBindingsClientForLocation, which creates clients with opts.
+func NewTagBindingsClientFactory(opts ...option.ClientOption) TagBindingsClientFactory {
+	return func(ctx context.Context, location string) (*resourcemanager.TagBindingsClient, error) {
+		location = bindingsLocation(location)
+		if location == "" {
+			return resourcemanager.NewTagBindingsClient(ctx, opts...)
+		}
+		endpoint := option.WithEndpoint(fmt.Sprintf("%s-cloudresourcemanager.googleapis.com:443", location))
+		return resourcemanager.NewTagBindingsClient(ctx, append([]option.ClientOption{endpoint}, opts...)...)
+	}
+}
+
+// bindingsLocation returns the region whose Resource Manager endpoint serves the tag bindings of resources in
+// location, or "" for the global endpoint. Zones like "europe-west1-b" are served by the endpoint of their region.
+// There are no endpoints for multi-regions like "eu" or "us" and dual-regions like "eur4", their resources are
+// served by the global endpoint like global resources.
+func bindingsLocation(location string) string {
+	location = strings.ToLower(location)
+	parts := strings.Split(location, "-")
+	switch {
+	case location == "global" || len(parts) == 1:
+		return ""
+	case len(parts) == 3:
+		return parts[0] + "-" + parts[1]
+	default:
+		return location
+	}
+}
+
+func NewTagsManager(keysClient *resourcemanager.TagKeysClient, valuesClient *resourcemanager.TagValuesClient, projectClient *resourcemanager.ProjectsClient, opts ...Option) TagsManager {
+	m := &tagsManager{
 		keysClient:     keysClient,
 		valuesClient:   valuesClient,
 		projectsClient: projectClient,
-		cache:          cache.New(tagCacheDuration, tagCacheDuration),
+		writer:         &apiTagWriter{keysClient: keysClient, valuesClient: valuesClient},
+		allowCreate:    true,
+
+		bindingsClientFactory: NewTagBindingsClientForLocation,
+
+		cache:            NewMemoryCache(),
+		cacheTTL:         DefaultCacheTTL,
+		negativeCacheTTL: DefaultNegativeCacheTTL,
+	}
+	for _, opt := range opts {
+		opt(m)
 	}
+	return m
 }
 
-func (m *tagsManager) LookupKey(ctx context.Context, projectID string, key string) (*resourcemanagerpb.TagKey, error) {
-	cacheKey := cacheKeyTagKey(key)
-	cachedKey, found := m.cache.Get(cacheKey)
-	if found {
+// ValidateTagParent checks that parent is an organization or project resource name. Projects must be named by their
+// ID, as namespaced names of tags start with the project ID, never the project number.
+func ValidateTagParent(parent string) error {
+	for _, prefix := range []string{"organizations/", "projects/"} {
+		if id, found := strings.CutPrefix(parent, prefix); found && id != "" && !strings.Contains(id, "/") {
+			if prefix == "projects/" && isProjectNumber(id) {
+				return fmt.Errorf("invalid tag parent %q: projects must be given by ID, not by number", parent)
+			}
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid tag parent %q: must be organizations/<id> or projects/<id>", parent)
+}
+
+// isProjectNumber reports whether id is a project number rather than a project ID, which starts with a letter.
+func isProjectNumber(id string) bool {
+	for _, r := range id {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// ParseKeyParents parses a comma separated list of key=parent pairs.
+func ParseKeyParents(in string) (map[string]string, error) {
+	parents := make(map[string]string)
+	for _, pair := range strings.Split(in, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		key, parent, found := strings.Cut(pair, "=")
+		if !found || key == "" {
+			return nil, fmt.Errorf("invalid key parent %q: must be key=parent", pair)
+		}
+		if err := ValidateTagParent(parent); err != nil {
+			return nil, err
+		}
+		parents[key] = parent
+	}
+	return parents, nil
+}
+
+// keyParent returns the resource name under which the given tag key lives.
+func (m *tagsManager) keyParent(projectID string, key string) string {
+	if parent, ok := m.keyParents[key]; ok {
+		return parent
+	}
+	if m.tagParent != "" {
+		return m.tagParent
+	}
+	return fmt.Sprintf("projects/%s", projectID)
+}
+
+// TagParents returns all distinct parents tag keys may live under for the given projects.
+func (m *tagsManager) TagParents(projectIDs ...string) []string {
+	seen := make(map[string]bool)
+	var parents []string
+	add := func(parent string) {
+		if !seen[parent] {
+			seen[parent] = true
+			parents = append(parents, parent)
+		}
+	}
+
+	if m.tagParent != "" {
+		add(m.tagParent)
+	} else {
+		for _, projectID := range projectIDs {
+			add(fmt.Sprintf("projects/%s", projectID))
+		}
+	}
+	for _, parent := range m.keyParents {
+		add(parent)
+	}
+	return parents
+}
+
+// tagNamespace returns the prefix used in namespaced tag names for a parent,
+// i.e. the organization ID or the project ID.
+func tagNamespace(parent string) string {
+	parent = strings.TrimPrefix(parent, "organizations/")
+	return strings.TrimPrefix(parent, "projects/")
+}
+
+// EnsureKey coalesces concurrent calls for the same tag key, so it is created at most once.
+func (m *tagsManager) EnsureKey(ctx context.Context, projectID string, key string) (*resourcemanagerpb.TagKey, error) {
+	cacheKey := cacheKeyTagKey(m.keyParent(projectID, key), key)
+	return coalesce(ctx, &m.inflight, "ensure_key", "ensure:"+cacheKey+"|"+coalescingScope(ctx), func(ctx context.Context) (*resourcemanagerpb.TagKey, error) {
+		return m.ensureKey(ctx, projectID, key)
+	})
+}
+
+func (m *tagsManager) ensureKey(ctx context.Context, projectID string, key string) (*resourcemanagerpb.TagKey, error) {
+	if err := m.awaitOperation(ctx, cacheKeyTagKey(m.keyParent(projectID, key), key)); err != nil {
+		return nil, err
+	}
+	tagKey, err := m.GetKey(ctx, projectID, key)
+	if IsNotFound(err) {
+		if !m.allowCreate {
+			return nil, fmt.Errorf("%w and creating tags is disabled", err)
+		}
+		return m.CreateKey(ctx, projectID, key)
+	}
+	if err == nil && m.adoptExisting && !IsOwned(tagKey.Description) {
+		tagKey = m.adoptKey(ctx, cacheKeyTagKey(m.keyParent(projectID, key), key), tagKey)
+	}
+	return tagKey, err
+}
+
+// GetKey coalesces concurrent lookups of the same tag key.
+func (m *tagsManager) GetKey(ctx context.Context, projectID string, key string) (*resourcemanagerpb.TagKey, error) {
+	cacheKey := cacheKeyTagKey(m.keyParent(projectID, key), key)
+	return coalesce(ctx, &m.inflight, "get_key", "get:"+cacheKey, func(ctx context.Context) (*resourcemanagerpb.TagKey, error) {
+		return m.getKey(ctx, projectID, key)
+	})
+}
+
+func (m *tagsManager) getKey(ctx context.Context, projectID string, key string) (*resourcemanagerpb.TagKey, error) {
+	parent := m.keyParent(projectID, key)
+	cacheKey := cacheKeyTagKey(parent, key)
+	if cachedKey, found, err := m.cacheGet(cacheKey); found {
+		if err != nil {
+			return nil, err
+		}
 		return cachedKey.(*resourcemanagerpb.TagKey), nil
 	}
 
 	tagKey, err := m.keysClient.GetNamespacedTagKey(ctx, &resourcemanagerpb.GetNamespacedTagKeyRequest{
-		Name: fmt.Sprintf("%s/%s", projectID, key),
+		Name: fmt.Sprintf("%s/%s", tagNamespace(parent), key),
 	})
+	if isPermissionDenied(err) {
+		tagKey, err = m.findKey(ctx, parent, key)
+	} else if err != nil {
+		err = fmt.Errorf("failed to lookup tag key: %w", err)
+	}
 	if err != nil {
-		var ae *apierror.APIError
-		if errors.As(err, &ae) && ae.GRPCStatus().Code() == codes.PermissionDenied {
-			return m.CreateKey(ctx, projectID, key)
+		if IsNotFound(err) {
+			m.cacheNotFound(cacheKey, err)
 		}
-		return nil, fmt.Errorf("failed to lookup tag key: %w", err)
+		return nil, err
 	}
 
-	m.cache.Set(cacheKey, tagKey, tagCacheDuration)
+	m.cacheSet(cacheKey, tagKey.Name, tagKey)
 	return tagKey, nil
 }
 
+// findKey searches the tag keys of the parent, telling a missing tag key apart from a permission problem.
+func (m *tagsManager) findKey(ctx context.Context, parent string, key string) (*resourcemanagerpb.TagKey, error) {
+	keys, err := m.ListKeys(ctx, parent)
+	if err != nil {
+		return nil, fmt.Errorf("failed to lookup tag key %s/%s: %w", tagNamespace(parent), key, err)
+	}
+	for _, tagKey := range keys {
+		if tagKey.ShortName == key {
+			return tagKey, nil
+		}
+	}
+	return nil, fmt.Errorf("tag key %s/%s %w", tagNamespace(parent), key, ErrNotFound)
+}
+
+// CreateKey creates a tag key. If the creation does not complete right away, it returns an OperationPendingError
+// instead of waiting for it.
 func (m *tagsManager) CreateKey(ctx context.Context, projectID string, key string) (*resourcemanagerpb.TagKey, error) {
-	op, err := m.keysClient.CreateTagKey(ctx, &resourcemanagerpb.CreateTagKeyRequest{
-		TagKey: &resourcemanagerpb.TagKey{
-			Parent:    fmt.Sprintf("projects/%s", projectID),
-			ShortName: key,
-		},
-	})
+	parent := m.keyParent(projectID, key)
+	cacheKey := cacheKeyTagKey(parent, key)
+	tagKey := &resourcemanagerpb.TagKey{
+		Parent:      parent,
+		ShortName:   key,
+		Description: m.description(ctx),
+	}
+	if err := m.setPurpose(ctx, projectID, tagKey); err != nil {
+		return nil, err
+	}
+	created, operation, err := m.writer.createKey(ctx, tagKey)
+	if status.Code(err) == codes.AlreadyExists {
+		return m.rereadKey(ctx, projectID, key, cacheKey)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to create tag key: %w", err)
 	}
-	tagKey, err := op.Wait(ctx)
+	if operation != "" {
+		return nil, m.startOperation(cacheKey, &OperationPendingError{
+			Kind:      KindTagKey,
+			Name:      fmt.Sprintf("%s/%s", tagNamespace(parent), key),
+			Operation: operation,
+		})
+	}
+	log.FromContext(ctx).Info("created tag key", "tagKey", created.NamespacedName)
+	notifyTagKeyCreated(ctx, created)
+
+	m.cacheSet(cacheKey, created.Name, created)
+	return created, nil
+}
+
+// rereadKey looks up a tag key which was created concurrently, e.g. by another operator replica,
+// dropping the cached lookup which still reports it as missing.
+func (m *tagsManager) rereadKey(ctx context.Context, projectID string, key string, cacheKey string) (*resourcemanagerpb.TagKey, error) {
+	tagAlreadyExistsTotal.WithLabelValues("TagKey").Inc()
+	m.cache.Delete(cacheKey)
+	return m.GetKey(ctx, projectID, key)
+}
+
+// EnsureValue coalesces concurrent calls for the same tag value, so it is created at most once.
+// With WithTagUser, it also grants the principal binding the value TagUserRole on the tag key.
+func (m *tagsManager) EnsureValue(ctx context.Context, projectID string, key string, value string) (*resourcemanagerpb.TagValue, error) {
+	cacheKey := cacheKeyTagValue(m.keyParent(projectID, key), key, value)
+	tagValue, err := coalesce(ctx, &m.inflight, "ensure_value", "ensure:"+cacheKey+"|"+coalescingScope(ctx), func(ctx context.Context) (*resourcemanagerpb.TagValue, error) {
+		return m.ensureValue(ctx, projectID, key, value)
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to wait for tag key creation: %w", err)
+		return nil, err
+	}
+	// outside of the coalesced call, as the principal may differ between callers
+	if err := m.grantTagUser(ctx, projectID, key, tagValue); err != nil {
+		return nil, err
 	}
+	return tagValue, nil
+}
 
-	m.cache.Set(cacheKeyTagKey(key), tagKey, tagCacheDuration)
-	return tagKey, nil
+func (m *tagsManager) ensureValue(ctx context.Context, projectID string, key string, value string) (*resourcemanagerpb.TagValue, error) {
+	if err := m.awaitOperation(ctx, cacheKeyTagValue(m.keyParent(projectID, key), key, value)); err != nil {
+		return nil, err
+	}
+	tagValue, err := m.GetValue(ctx, projectID, key, value)
+	if IsNotFound(err) {
+		if !m.allowCreate {
+			return nil, fmt.Errorf("%w and creating tags is disabled", err)
+		}
+		return m.CreateValue(ctx, projectID, key, value)
+	}
+	if err == nil && m.adoptExisting && !IsOwned(tagValue.Description) {
+		tagValue = m.adoptValue(ctx, cacheKeyTagValue(m.keyParent(projectID, key), key, value), tagValue)
+	}
+	return tagValue, err
 }
 
-func (m *tagsManager) LookupValue(ctx context.Context, projectID string, key string, value string) (*resourcemanagerpb.TagValue, error) {
-	cacheKey := cacheKeyTagValue(key, value)
-	cachedValue, found := m.cache.Get(cacheKey)
-	if found {
+// GetValue coalesces concurrent lookups of the same tag value.
+func (m *tagsManager) GetValue(ctx context.Context, projectID string, key string, value string) (*resourcemanagerpb.TagValue, error) {
+	cacheKey := cacheKeyTagValue(m.keyParent(projectID, key), key, value)
+	return coalesce(ctx, &m.inflight, "get_value", "get:"+cacheKey, func(ctx context.Context) (*resourcemanagerpb.TagValue, error) {
+		return m.getValue(ctx, projectID, key, value)
+	})
+}
+
+func (m *tagsManager) getValue(ctx context.Context, projectID string, key string, value string) (*resourcemanagerpb.TagValue, error) {
+	parent := m.keyParent(projectID, key)
+	cacheKey := cacheKeyTagValue(parent, key, value)
+	if cachedValue, found, err := m.cacheGet(cacheKey); found {
+		if err != nil {
+			return nil, err
+		}
 		return cachedValue.(*resourcemanagerpb.TagValue), nil
 	}
 
 	tagValue, err := m.valuesClient.GetNamespacedTagValue(ctx, &resourcemanagerpb.GetNamespacedTagValueRequest{
-		Name: fmt.Sprintf("%s/%s/%s", projectID, key, value),
+		Name: fmt.Sprintf("%s/%s/%s", tagNamespace(parent), key, value),
 	})
+	if isPermissionDenied(err) {
+		tagValue, err = m.findValue(ctx, projectID, key, value)
+	} else if err != nil {
+		err = fmt.Errorf("failed to lookup tag value: %w", err)
+	}
 	if err != nil {
-		var ae *apierror.APIError
-		if errors.As(err, &ae) && ae.GRPCStatus().Code() == codes.PermissionDenied {
-			return m.CreateValue(ctx, projectID, key, value)
+		if IsNotFound(err) {
+			m.cacheNotFound(cacheKey, err)
 		}
-		return nil, fmt.Errorf("failed to lookup tag value: %w", err)
+		return nil, err
 	}
 
-	m.cache.Set(cacheKey, tagValue, tagCacheDuration)
+	m.cacheSet(cacheKey, tagValue.Name, tagValue)
 	return tagValue, nil
 }
 
+// findValue searches the values of the tag key, telling a missing tag value apart from a permission problem.
+func (m *tagsManager) findValue(ctx context.Context, projectID string, key string, value string) (*resourcemanagerpb.TagValue, error) {
+	tagKey, err := m.GetKey(ctx, projectID, key)
+	if err != nil {
+		return nil, err
+	}
+	values, err := m.ListValues(ctx, tagKey.Name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to lookup tag value %s/%s: %w", tagKey.NamespacedName, value, err)
+	}
+	for _, tagValue := range values {
+		if tagValue.ShortName == value {
+			return tagValue, nil
+		}
+	}
+	return nil, fmt.Errorf("tag value %s/%s %w", tagKey.NamespacedName, value, ErrNotFound)
+}
+
+// CreateValue creates a tag value, and its tag key if necessary. Like CreateKey, it does not wait for the creation.
 func (m *tagsManager) CreateValue(ctx context.Context, projectID string, key string, value string) (*resourcemanagerpb.TagValue, error) {
-	tagKey, err := m.LookupKey(ctx, projectID, key)
+	tagKey, err := m.EnsureKey(ctx, projectID, key)
 	if err != nil {
-		return nil, fmt.Errorf("failed to lookup tag key: %w", err)
+		return nil, err
 	}
 
-	op, err := m.valuesClient.CreateTagValue(ctx, &resourcemanagerpb.CreateTagValueRequest{
-		TagValue: &resourcemanagerpb.TagValue{
-			Parent:    tagKey.Name,
-			ShortName: value,
-		},
+	cacheKey := cacheKeyTagValue(m.keyParent(projectID, key), key, value)
+	tagValue, operation, err := m.writer.createValue(ctx, tagKey, &resourcemanagerpb.TagValue{
+		Parent:      tagKey.Name,
+		ShortName:   value,
+		Description: m.description(ctx),
 	})
+	if status.Code(err) == codes.AlreadyExists {
+		return m.rereadValue(ctx, projectID, key, value, cacheKey)
+	}
+	if status.Code(err) == codes.NotFound {
+		// the cached tag key was deleted in the meantime
+		m.Invalidate(tagKey.Name)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to create tag value: %w", err)
 	}
-	tagValue, err := op.Wait(ctx)
-	if err != nil {
-		return nil, fmt.Errorf("failed to wait for tag value creation: %w", err)
+	if operation != "" {
+		return nil, m.startOperation(cacheKey, &OperationPendingError{
+			Kind:      KindTagValue,
+			Name:      fmt.Sprintf("%s/%s", tagKey.NamespacedName, value),
+			Operation: operation,
+		})
 	}
+	log.FromContext(ctx).Info("created tag value", "tagValue", tagValue.NamespacedName)
+	notifyTagValueCreated(ctx, tagValue)
 
-	m.cache.Set(cacheKeyTagValue(key, value), tagValue, tagCacheDuration)
+	m.cacheSet(cacheKey, tagValue.Name, tagValue)
 	return tagValue, nil
 }
 
-func cacheKeyTagKey(key string) string {
-	return fmt.Sprintf("key:%s", key)
+// rereadValue looks up a tag value which was created concurrently, like rereadKey.
+func (m *tagsManager) rereadValue(ctx context.Context, projectID string, key string, value string, cacheKey string) (*resourcemanagerpb.TagValue, error) {
+	tagAlreadyExistsTotal.WithLabelValues("TagValue").Inc()
+	m.cache.Delete(cacheKey)
+	return m.GetValue(ctx, projectID, key, value)
 }
 
-func cacheKeyTagValue(key string, value string) string {
-	return fmt.Sprintf("value:%s:%s", key, value)
+// isPermissionDenied reports whether a namespaced lookup was denied. The API answers with PermissionDenied
+// for tag keys and values which do not exist as well, as it does not reveal whether an inaccessible tag exists.
+func isPermissionDenied(err error) bool {
+	var ae *apierror.APIError
+	return errors.As(err, &ae) && ae.GRPCStatus().Code() == codes.PermissionDenied
 }
 
 func (m *tagsManager) GetProjectInfo(ctx context.Context, projectID string) (*resourcemanagerpb.Project, error) {
@@ -177,54 +586,142 @@ func (m *tagsManager) GetProjectInfo(ctx context.Context, projectID string) (*re
 		return nil, fmt.Errorf("failed to get project: %v", err)
 	}
 
-	m.cache.Set(cacheKey, project, tagCacheDuration)
+	m.cache.Set(cacheKey, project, m.cacheTTL)
 	return project, nil
 }
 
-func (m *tagsManager) DeleteValueIfUnused(ctx context.Context, projectID string, key string, value string) error {
+// DeleteValueIfUnused deletes a tag value created by the operator, unless it is still bound to a resource.
+// It reports whether the value was deleted or is gone already.
+func (m *tagsManager) DeleteValueIfUnused(ctx context.Context, projectID string, key string, value string) (bool, error) {
+	if deletable, err := m.deletable(ctx, KindTagValue, value); err != nil || !deletable {
+		return false, err
+	}
 
-	req := &resourcemanagerpb.DeleteTagValueRequest{
-		Name: value,
+	operation, err := m.writer.deleteValue(ctx, value)
+	switch status.Code(err) {
+	case codes.OK:
+	case codes.NotFound:
+		m.Invalidate(value)
+		return true, nil
+	case codes.FailedPrecondition:
+		// tag value is in use
+		return false, nil
+	default:
+		return false, fmt.Errorf("failed to delete tag value %s: %w", value, err)
 	}
 
-	op, err := m.valuesClient.DeleteTagValue(ctx, req)
-	if err != nil {
-		var ae *apierror.APIError
-		if errors.As(err, &ae) && (ae.GRPCStatus().Code() == codes.FailedPrecondition || ae.GRPCStatus().Code() == codes.NotFound) {
-			// tag value is in use
-			return nil
-		}
-		return fmt.Errorf("failed to call tagValue deletion request: %w", err)
+	m.Invalidate(value)
+	if operation != "" {
+		// the deletion completes in the background, there is nothing left to do for the caller
+		log.FromContext(ctx).Info("tag value deletion in progress", "tagValue", value, "operation", operation)
 	}
+	return true, nil
+}
 
-	_, err = op.Wait(ctx)
-	if err != nil {
-		return fmt.Errorf("failed to delete the tagValue %w", err)
+// DeleteKeyIfUnused deletes a tag key created by the operator, unless it still has values.
+func (m *tagsManager) DeleteKeyIfUnused(ctx context.Context, projectID string, key string) error {
+	if deletable, err := m.deletable(ctx, KindTagKey, key); err != nil || !deletable {
+		return err
+	}
+
+	operation, err := m.writer.deleteKey(ctx, key)
+	switch status.Code(err) {
+	case codes.OK:
+	case codes.NotFound:
+		m.Invalidate(key)
+		return nil
+	case codes.FailedPrecondition:
+		// tag key still has values
+		return nil
+	default:
+		return fmt.Errorf("failed to delete tag key %s: %w", key, err)
 	}
 
-	m.cache.Delete(cacheKeyTagValue(key, value))
+	m.Invalidate(key)
+	if operation != "" {
+		log.FromContext(ctx).Info("tag key deletion in progress", "tagKey", key, "operation", operation)
+	}
 	return nil
 }
 
-func (m *tagsManager) DeleteKeyIfUnused(ctx context.Context, projectID string, key string) error {
+func (m *tagsManager) ListKeys(ctx context.Context, parent string) ([]*resourcemanagerpb.TagKey, error) {
+	it := m.keysClient.ListTagKeys(ctx, &resourcemanagerpb.ListTagKeysRequest{
+		Parent: parent,
+	})
+
+	var keys []*resourcemanagerpb.TagKey
+	for {
+		key, err := it.Next()
+		if errors.Is(err, iterator.Done) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to list tag keys: %w", err)
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+func (m *tagsManager) ListValues(ctx context.Context, key string) ([]*resourcemanagerpb.TagValue, error) {
+	it := m.valuesClient.ListTagValues(ctx, &resourcemanagerpb.ListTagValuesRequest{
+		Parent: key,
+	})
 
-	// Attempt to delete the tag key
-	req := &resourcemanagerpb.DeleteTagKeyRequest{
-		Name: key,
+	var values []*resourcemanagerpb.TagValue
+	for {
+		value, err := it.Next()
+		if errors.Is(err, iterator.Done) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to list tag values: %w", err)
+		}
+		values = append(values, value)
 	}
-	op, err := m.keysClient.DeleteTagKey(ctx, req)
+	return values, nil
+}
+
+// ListBindings returns the tag bindings directly attached to the resource with the given full resource name.
+func (m *tagsManager) ListBindings(ctx context.Context, location string, parent string) ([]*resourcemanagerpb.TagBinding, error) {
+	client, err := m.bindingsClient(ctx, location)
 	if err != nil {
-		var ae *apierror.APIError
-		if errors.As(err, &ae) && (ae.GRPCStatus().Code() == codes.FailedPrecondition || ae.GRPCStatus().Code() == codes.NotFound) {
-			return nil
+		return nil, err
+	}
+
+	it := client.ListTagBindings(ctx, &resourcemanagerpb.ListTagBindingsRequest{
+		Parent: parent,
+	})
+
+	var bindings []*resourcemanagerpb.TagBinding
+	for {
+		binding, err := it.Next()
+		if errors.Is(err, iterator.Done) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to list tag bindings: %w", err)
 		}
-		return fmt.Errorf("failed to call tagKey deletion request: %w", err)
+		bindings = append(bindings, binding)
+	}
+	return bindings, nil
+}
+
+func (m *tagsManager) bindingsClient(ctx context.Context, location string) (*resourcemanager.TagBindingsClient, error) {
+	// resources in the zones of a region share the client of the region
+	location = bindingsLocation(location)
+	if client, found := m.bindingsClients.Load(location); found {
+		return client.(*resourcemanager.TagBindingsClient), nil
 	}
 
-	_, err = op.Wait(ctx)
+	// the client outlives the reconcile, so it must not be bound to its cancellation
+	client, err := m.bindingsClientFactory(context.WithoutCancel(ctx), location)
 	if err != nil {
-		return fmt.Errorf("failed to delete the tagKey %w", err)
+		return nil, fmt.Errorf("failed to create tag bindings client for location %s: %w", location, err)
 	}
-	m.cache.Delete(cacheKeyTagKey(key))
-	return nil
+	actual, loaded := m.bindingsClients.LoadOrStore(location, client)
+	if loaded {
+		_ = client.Close()
+	}
+	return actual.(*resourcemanager.TagBindingsClient), nil
 }
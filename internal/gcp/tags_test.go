@@ -50,6 +50,13 @@ func (s *fakeTagKeysServer) GetNamespacedTagKey(ctx context.Context, req *resour
 			ShortName: "existing-key",
 		}, nil
 	}
+	if req.Name == "123456789/org-key" {
+		return &resourcemanagerpb.TagKey{
+			Name:      "tagKeys/987",
+			Parent:    "organizations/123456789",
+			ShortName: "org-key",
+		}, nil
+	}
 	return nil, fmt.Errorf("tag key not found")
 }
 
@@ -94,11 +101,43 @@ func TestLookupKeyWithFakeGRPCServer(t *testing.T) {
 
 	mgr := NewTagsManager(keysClient, nil, nil)
 
-	key, err := mgr.LookupKey(ctx, "test-project", "existing-key")
-	assert.NoError(t, err, "LookupKey failed")
+	key, err := mgr.EnsureKey(ctx, "test-project", "existing-key")
+	assert.NoError(t, err, "EnsureKey failed")
 	assert.Equal(t, "projects/test-project/existing-key", key.Name, "Expected key name 'projects/test-project/existing-key'")
 }
 
+func TestLookupKeyWithTagParentWithFakeGRPCServer(t *testing.T) {
+	lis := bufconn.Listen(bufSize)
+
+	s := grpc.NewServer()
+	resourcemanagerpb.RegisterTagKeysServer(s, &fakeTagKeysServer{})
+
+	go func() {
+		if err := s.Serve(lis); err != nil && err != grpc.ErrServerStopped {
+			t.Errorf("Server exited with error: %v", err)
+		}
+	}()
+	defer s.Stop()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	conn, err := grpc.DialContext(ctx, "bufnet", grpc.WithContextDialer(func(ctx context.Context, s string) (net.Conn, error) {
+		return bufDialer(lis)
+	}), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	assert.NoError(t, err, "Failed to dial bufnet")
+	defer conn.Close()
+
+	keysClient, err := resourcemanager.NewTagKeysClient(ctx, option.WithGRPCConn(conn))
+	assert.NoError(t, err, "Failed to create TagKeysClient")
+
+	mgr := NewTagsManager(keysClient, nil, nil, WithTagParent("organizations/123456789"))
+
+	key, err := mgr.EnsureKey(ctx, "test-project", "org-key")
+	assert.NoError(t, err, "EnsureKey failed")
+	assert.Equal(t, "tagKeys/987", key.Name, "Expected key name 'tagKeys/987'")
+}
+
 func TestLookupValueWithFakeGRPCServer(t *testing.T) {
 	lis := bufconn.Listen(bufSize)
 
@@ -130,8 +169,8 @@ func TestLookupValueWithFakeGRPCServer(t *testing.T) {
 
 	mgr := NewTagsManager(nil, valuesClient, nil)
 
-	value, err := mgr.LookupValue(ctx, "test-project", "existing-key", "existing-value")
-	assert.NoError(t, err, "LookupValue failed")
+	value, err := mgr.EnsureValue(ctx, "test-project", "existing-key", "existing-value")
+	assert.NoError(t, err, "EnsureValue failed")
 	assert.Equal(t, "projects/test-project/existing-key/existing-value", value.Name, "Expected value name 'projects/test-project/existing-key/existing-value'")
 
 	cancel()
@@ -141,74 +180,240 @@ func TestLookupValueWithFakeGRPCServer(t *testing.T) {
 	lis.Close()
 }
 
+func TestGetValueTellsMissingFromDenied(t *testing.T) {
+	ctx := context.Background()
+	m, _ := newCountingTagsManager(t)
+
+	value, err := m.GetValue(ctx, "project-a", "env", "prod")
+	assert.NoError(t, err)
+	assert.Equal(t, "tagValues/project-a-prod", value.Name)
+
+	_, err = m.GetValue(ctx, "project-a", "env", "dev")
+	assert.True(t, IsNotFound(err), "value missing from the listing of its key should be reported as not found, got %v", err)
+
+	// listing the keys is denied as well, so the key may exist but be inaccessible
+	_, err = m.GetValue(ctx, "project-a", "team", "payments")
+	assert.Error(t, err)
+	assert.False(t, IsNotFound(err), "denied lookups must not be reported as not found")
+}
+
+func TestEnsureValueWithoutCreate(t *testing.T) {
+	ctx := context.Background()
+	m, _ := newCountingTagsManager(t, WithAllowCreate(false))
+
+	value, err := m.EnsureValue(ctx, "project-a", "env", "prod")
+	assert.NoError(t, err, "existing values can still be used")
+	assert.Equal(t, "tagValues/project-a-prod", value.Name)
+
+	_, err = m.EnsureValue(ctx, "project-a", "env", "dev")
+	assert.True(t, IsNotFound(err))
+	assert.ErrorContains(t, err, "creating tags is disabled")
+}
+
 func TestCacheKeyTagKey(t *testing.T) {
 	testCases := []struct {
-		name string
-		key  string
-		want string
+		name   string
+		parent string
+		key    string
+		want   string
 	}{
 		{
-			name: "simple key",
-			key:  "my-key",
-			want: "key:my-key",
+			name:   "project parent",
+			parent: "projects/project-a",
+			key:    "my-key",
+			want:   "key:projects/project-a/my-key",
 		},
 		{
-			name: "empty key",
-			key:  "",
-			want: "key:",
+			name:   "organization parent",
+			parent: "organizations/123456789",
+			key:    "my-key",
+			want:   "key:organizations/123456789/my-key",
 		},
 		{
-			name: "key with special characters",
-			key:  "key-with-special_chars",
-			want: "key:key-with-special_chars",
+			name:   "key with special characters",
+			parent: "projects/project-a",
+			key:    "key-with-special_chars",
+			want:   "key:projects/project-a/key-with-special_chars",
 		},
 	}
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			got := cacheKeyTagKey(tc.key)
-			assert.Equal(t, tc.want, got, fmt.Sprintf("cacheKeyTagKey(%q) should return %q", tc.key, tc.want))
+			got := cacheKeyTagKey(tc.parent, tc.key)
+			assert.Equal(t, tc.want, got, fmt.Sprintf("cacheKeyTagKey(%q, %q) should return %q", tc.parent, tc.key, tc.want))
 		})
 	}
+
+	assert.NotEqual(t, cacheKeyTagKey("projects/project-a", "env"), cacheKeyTagKey("projects/project-b", "env"),
+		"keys of different parents must not share a cache entry")
 }
 
 func TestCacheKeyTagValue(t *testing.T) {
 	testCases := []struct {
-		name  string
-		key   string
-		value string
-		want  string
+		name   string
+		parent string
+		key    string
+		value  string
+		want   string
+	}{
+		{
+			name:   "simple key and value",
+			parent: "projects/project-a",
+			key:    "my-key",
+			value:  "my-value",
+			want:   "value:projects/project-a/my-key/my-value",
+		},
+		{
+			name:   "empty value",
+			parent: "projects/project-a",
+			key:    "my-key",
+			value:  "",
+			want:   "value:projects/project-a/my-key/",
+		},
+		{
+			name:   "key and value with special characters",
+			parent: "organizations/123456789",
+			key:    "key-with-special_chars",
+			value:  "value-with-special_chars",
+			want:   "value:organizations/123456789/key-with-special_chars/value-with-special_chars",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := cacheKeyTagValue(tc.parent, tc.key, tc.value)
+			assert.Equal(t, tc.want, got, fmt.Sprintf("cacheKeyTagValue(%q, %q, %q) should return %q", tc.parent, tc.key, tc.value, tc.want))
+		})
+	}
+
+	assert.NotEqual(t, cacheKeyTagValue("projects/project-a", "env", "prod"), cacheKeyTagValue("projects/project-b", "env", "prod"),
+		"values of different parents must not share a cache entry")
+}
+
+func TestKeyParent(t *testing.T) {
+	testCases := []struct {
+		name       string
+		opts       []Option
+		projectID  string
+		key        string
+		wantParent string
+		wantNS     string
 	}{
 		{
-			name:  "simple key and value",
-			key:   "my-key",
-			value: "my-value",
-			want:  "value:my-key:my-value",
+			name:       "default project parent",
+			projectID:  "test-project",
+			key:        "team",
+			wantParent: "projects/test-project",
+			wantNS:     "test-project",
+		},
+		{
+			name:       "global organization parent",
+			opts:       []Option{WithTagParent("organizations/123456789")},
+			projectID:  "test-project",
+			key:        "team",
+			wantParent: "organizations/123456789",
+			wantNS:     "123456789",
+		},
+		{
+			name:       "global tags project parent",
+			opts:       []Option{WithTagParent("projects/tags-project")},
+			projectID:  "test-project",
+			key:        "team",
+			wantParent: "projects/tags-project",
+			wantNS:     "tags-project",
+		},
+		{
+			name: "per key parent overrides global parent",
+			opts: []Option{
+				WithTagParent("organizations/123456789"),
+				WithKeyParents(map[string]string{"env": "projects/tags-project"}),
+			},
+			projectID:  "test-project",
+			key:        "env",
+			wantParent: "projects/tags-project",
+			wantNS:     "tags-project",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			m := NewTagsManager(nil, nil, nil, tc.opts...).(*tagsManager)
+			parent := m.keyParent(tc.projectID, tc.key)
+			assert.Equal(t, tc.wantParent, parent)
+			assert.Equal(t, tc.wantNS, tagNamespace(parent))
+		})
+	}
+}
+
+func TestBindingsLocation(t *testing.T) {
+	testCases := []struct {
+		location string
+		want     string
+	}{
+		{location: "", want: ""},
+		{location: "global", want: ""},
+		{location: "europe-west1", want: "europe-west1"},
+		{location: "EUROPE-WEST1", want: "europe-west1"},
+		{location: "us-central1-a", want: "us-central1"},
+		{location: "northamerica-northeast1-b", want: "northamerica-northeast1"},
+		{location: "EU", want: ""},
+		{location: "us", want: ""},
+		{location: "eur4", want: ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.location, func(t *testing.T) {
+			assert.Equal(t, tc.want, bindingsLocation(tc.location))
+		})
+	}
+}
+
+func TestParseKeyParents(t *testing.T) {
+	testCases := []struct {
+		name    string
+		in      string
+		want    map[string]string
+		wantErr bool
+	}{
+		{
+			name: "empty",
+			in:   "",
+			want: map[string]string{},
+		},
+		{
+			name: "multiple pairs",
+			in:   "team=organizations/123, env=projects/tags-project",
+			want: map[string]string{
+				"team": "organizations/123",
+				"env":  "projects/tags-project",
+			},
 		},
 		{
-			name:  "empty key",
-			key:   "",
-			value: "my-value",
-			want:  "value::my-value",
+			name:    "missing parent",
+			in:      "team",
+			wantErr: true,
 		},
 		{
-			name:  "empty value",
-			key:   "my-key",
-			value: "",
-			want:  "value:my-key:",
+			name:    "invalid parent",
+			in:      "team=folders/123",
+			wantErr: true,
 		},
 		{
-			name:  "key and value with special characters",
-			key:   "key-with-special_chars",
-			value: "value-with-special_chars",
-			want:  "value:key-with-special_chars:value-with-special_chars",
+			name:    "project number",
+			in:      "team=projects/123456789",
+			wantErr: true,
 		},
 	}
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			got := cacheKeyTagValue(tc.key, tc.value)
-			assert.Equal(t, tc.want, got, fmt.Sprintf("cacheKeyTagValue(%q, %q) should return %q", tc.key, tc.value, tc.want))
+			got, err := ParseKeyParents(tc.in)
+			if tc.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.want, got)
 		})
 	}
 }
@@ -0,0 +1,152 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gcp
+
+import (
+	"context"
+	"slices"
+
+	"cloud.google.com/go/iam/apiv1/iampb"
+	resourcemanager "cloud.google.com/go/resourcemanager/apiv3"
+	"cloud.google.com/go/resourcemanager/apiv3/resourcemanagerpb"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+)
+
+// tagWriter performs all changes the tagsManager makes to tag keys and values, so dry-run can record them instead,
+// see WithDryRun. Creations return the name of their operation instead of the created tag while it is still running.
+type tagWriter interface {
+	createKey(ctx context.Context, tagKey *resourcemanagerpb.TagKey) (*resourcemanagerpb.TagKey, string, error)
+	createValue(ctx context.Context, tagKey *resourcemanagerpb.TagKey, tagValue *resourcemanagerpb.TagValue) (*resourcemanagerpb.TagValue, string, error)
+	// updateKey and updateValue change the description of a tag and wait for the update.
+	updateKey(ctx context.Context, tagKey *resourcemanagerpb.TagKey) (*resourcemanagerpb.TagKey, error)
+	updateValue(ctx context.Context, tagValue *resourcemanagerpb.TagValue) (*resourcemanagerpb.TagValue, error)
+	// deleteKey and deleteValue return the name of the deletion operation while it is still running.
+	deleteKey(ctx context.Context, name string) (string, error)
+	deleteValue(ctx context.Context, name string) (string, error)
+	// addTagUser adds principal to the unconditional TagUserRole binding of the tag key, unless it is a member already.
+	addTagUser(ctx context.Context, tagKey *resourcemanagerpb.TagKey, principal string) error
+}
+
+// apiTagWriter changes tags through the Resource Manager API.
+type apiTagWriter struct {
+	keysClient   *resourcemanager.TagKeysClient
+	valuesClient *resourcemanager.TagValuesClient
+}
+
+func (w *apiTagWriter) createKey(ctx context.Context, tagKey *resourcemanagerpb.TagKey) (*resourcemanagerpb.TagKey, string, error) {
+	op, err := w.keysClient.CreateTagKey(ctx, &resourcemanagerpb.CreateTagKeyRequest{TagKey: tagKey})
+	if err != nil {
+		return nil, "", err
+	}
+	if !op.Done() {
+		return nil, op.Name(), nil
+	}
+	created, err := op.Wait(ctx)
+	return created, "", err
+}
+
+func (w *apiTagWriter) createValue(ctx context.Context, _ *resourcemanagerpb.TagKey, tagValue *resourcemanagerpb.TagValue) (*resourcemanagerpb.TagValue, string, error) {
+	op, err := w.valuesClient.CreateTagValue(ctx, &resourcemanagerpb.CreateTagValueRequest{TagValue: tagValue})
+	if err != nil {
+		return nil, "", err
+	}
+	if !op.Done() {
+		return nil, op.Name(), nil
+	}
+	created, err := op.Wait(ctx)
+	return created, "", err
+}
+
+func (w *apiTagWriter) updateKey(ctx context.Context, tagKey *resourcemanagerpb.TagKey) (*resourcemanagerpb.TagKey, error) {
+	op, err := w.keysClient.UpdateTagKey(ctx, &resourcemanagerpb.UpdateTagKeyRequest{
+		TagKey:     tagKey,
+		UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"description"}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return op.Wait(ctx)
+}
+
+func (w *apiTagWriter) updateValue(ctx context.Context, tagValue *resourcemanagerpb.TagValue) (*resourcemanagerpb.TagValue, error) {
+	op, err := w.valuesClient.UpdateTagValue(ctx, &resourcemanagerpb.UpdateTagValueRequest{
+		TagValue:   tagValue,
+		UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"description"}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return op.Wait(ctx)
+}
+
+func (w *apiTagWriter) deleteKey(ctx context.Context, name string) (string, error) {
+	op, err := w.keysClient.DeleteTagKey(ctx, &resourcemanagerpb.DeleteTagKeyRequest{Name: name})
+	if err != nil {
+		return "", err
+	}
+	if !op.Done() {
+		return op.Name(), nil
+	}
+	_, err = op.Wait(ctx)
+	return "", err
+}
+
+func (w *apiTagWriter) deleteValue(ctx context.Context, name string) (string, error) {
+	op, err := w.valuesClient.DeleteTagValue(ctx, &resourcemanagerpb.DeleteTagValueRequest{Name: name})
+	if err != nil {
+		return "", err
+	}
+	if !op.Done() {
+		return op.Name(), nil
+	}
+	_, err = op.Wait(ctx)
+	return "", err
+}
+
+func (w *apiTagWriter) addTagUser(ctx context.Context, tagKey *resourcemanagerpb.TagKey, principal string) error {
+	policy, changed, err := tagUserPolicy(ctx, w.keysClient, tagKey.Name, principal)
+	if err != nil || !changed {
+		return err
+	}
+	_, err = w.keysClient.SetIamPolicy(ctx, &iampb.SetIamPolicyRequest{Resource: tagKey.Name, Policy: policy})
+	return err
+}
+
+// tagUserPolicy reads the IAM policy of a tag key and adds principal to its unconditional TagUserRole binding.
+// It reports whether the policy changed, i.e. whether principal was not a member yet.
+func tagUserPolicy(ctx context.Context, keysClient *resourcemanager.TagKeysClient, name string, principal string) (*iampb.Policy, bool, error) {
+	policy, err := keysClient.GetIamPolicy(ctx, &iampb.GetIamPolicyRequest{Resource: name})
+	if err != nil {
+		return nil, false, err
+	}
+	var binding *iampb.Binding
+	for _, b := range policy.Bindings {
+		if b.Role == TagUserRole && b.Condition == nil {
+			binding = b
+			break
+		}
+	}
+	if binding == nil {
+		binding = &iampb.Binding{Role: TagUserRole}
+		policy.Bindings = append(policy.Bindings, binding)
+	}
+	if slices.Contains(binding.Members, principal) {
+		return policy, false, nil
+	}
+	binding.Members = append(binding.Members, principal)
+	return policy, true, nil
+}
@@ -0,0 +1,385 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package policy decides which tags a Config Connector resource should carry.
+package policy
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	taggingv1alpha1 "github.com/deliveryhero/gcp-config-connector-tagging-operator/api/v1alpha1"
+	"github.com/deliveryhero/gcp-config-connector-tagging-operator/internal/util"
+)
+
+// LabelMatcher filters the labels which become tags, as created by util.LimitLabelsWithRegex.
+type LabelMatcher func(map[string]string) map[string]string
+
+// Tag is a tag a resource should carry.
+type Tag struct {
+	// Key is the short name of the tag key.
+	Key string
+	// Value is the short name of the tag value.
+	Value string
+	// Label is the label key the tag was derived from.
+	Label string
+	// Policy is the TaggingPolicy which produced the tag, empty for tags from the fallback LabelMatcher.
+	Policy string
+}
+
+// Result is the outcome of evaluating the tagging policies for a resource.
+type Result struct {
+	// Tags are sorted by tag key.
+	Tags []Tag
+	// MissingLabels lists the labels required by a policy which the resource lacks.
+	MissingLabels []string
+	// InvalidTags lists the labels which cannot be mapped to legal tag keys and values.
+	InvalidTags []InvalidTag
+}
+
+// InvalidTag is a label which cannot become a tag.
+type InvalidTag struct {
+	// Label is the label key.
+	Label string
+	// Reason explains why the label cannot be mapped.
+	Reason string
+}
+
+// Evaluator maps labels to tags using all TaggingPolicies of the cluster.
+// Without any TaggingPolicy, the fallback LabelMatcher decides which labels become tags with the label key as tag key.
+type Evaluator struct {
+	reader   client.Reader
+	fallback LabelMatcher
+	sources  []Source
+
+	namespaceLabels      LabelMatcher
+	namespaceAnnotations LabelMatcher
+	normalizer           *util.ShortNameNormalizer
+
+	regexps sync.Map
+}
+
+// Option configures optional behaviour of the Evaluator.
+type Option func(*Evaluator)
+
+// WithNamespaceLabels merges the matching labels of a resource's namespace into its labels.
+// Labels of the resource itself take precedence.
+func WithNamespaceLabels(matcher LabelMatcher) Option {
+	return func(e *Evaluator) {
+		e.namespaceLabels = matcher
+	}
+}
+
+// WithNamespaceAnnotations merges the matching annotations of a resource's namespace into its labels.
+// Labels of the resource itself and of the namespace take precedence.
+func WithNamespaceAnnotations(matcher LabelMatcher) Option {
+	return func(e *Evaluator) {
+		e.namespaceAnnotations = matcher
+	}
+}
+
+// WithNormalizer maps tag keys and values to legal short names, see util.ShortNameNormalizer.
+// By default they are only validated.
+func WithNormalizer(normalizer *util.ShortNameNormalizer) Option {
+	return func(e *Evaluator) {
+		e.normalizer = normalizer
+	}
+}
+
+func NewEvaluator(reader client.Reader, fallback LabelMatcher, opts ...Option) *Evaluator {
+	e := &Evaluator{reader: reader, fallback: fallback, sources: []Source{SourceLabels}, normalizer: &util.ShortNameNormalizer{}}
+	for _, opt := range opts {
+		opt(e)
+	}
+	return e
+}
+
+// UsesNamespaces reports whether the tags of a resource depend on its namespace.
+func (e *Evaluator) UsesNamespaces() bool {
+	return e.namespaceLabels != nil || e.namespaceAnnotations != nil
+}
+
+// Evaluate returns the tags the resource should carry.
+func (e *Evaluator) Evaluate(ctx context.Context, resource client.Object) (*Result, error) {
+	policies, err := e.policies(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defaults, err := e.namespaceDefaults(ctx, resource.GetNamespace())
+	if err != nil {
+		return nil, err
+	}
+	resourceLabels, invalidSources := e.resourceLabels(resource)
+	if len(policies) == 0 {
+		// namespace defaults were filtered already and must not be filtered by the fallback again
+		result := e.normalize(e.evaluateFallback(mergeLabels(defaults, e.fallback(resourceLabels))))
+		result.InvalidTags = append(invalidSources, result.InvalidTags...)
+		return result, nil
+	}
+
+	result := &Result{}
+	seen := make(map[string]bool)
+	kind := resource.GetObjectKind().GroupVersionKind().Kind
+	labels := mergeLabels(defaults, resourceLabels)
+	for _, policy := range policies {
+		for _, rule := range policy.Spec.Rules {
+			// invalid rules are reported in the policy status and must not block other policies
+			if ValidateRule(rule) != nil || !ruleApplies(rule, kind, resource.GetNamespace()) {
+				continue
+			}
+			tags, err := e.evaluateRule(rule, labels)
+			if err != nil {
+				return nil, fmt.Errorf("invalid rule in tagging policy %s: %w", policy.Name, err)
+			}
+			if len(tags) == 0 && rule.Required {
+				result.MissingLabels = append(result.MissingLabels, ruleLabel(rule))
+			}
+			for _, tag := range tags {
+				if seen[tag.Key] {
+					continue
+				}
+				seen[tag.Key] = true
+				tag.Policy = policy.Name
+				result.Tags = append(result.Tags, tag)
+			}
+		}
+	}
+
+	result = e.normalize(result)
+	result.InvalidTags = append(invalidSources, result.InvalidTags...)
+	return result, nil
+}
+
+// normalize maps the tags of result to legal short names, moving those which cannot be mapped to InvalidTags.
+// Tags are sorted by key afterwards, the first of several labels mapped to the same tag key wins.
+func (e *Evaluator) normalize(result *Result) *Result {
+	tags := result.Tags
+	result.Tags = nil
+	seen := make(map[string]string)
+	for _, tag := range tags {
+		key, err := e.normalizer.Key(tag.Key)
+		if err != nil {
+			result.InvalidTags = append(result.InvalidTags, InvalidTag{Label: tag.Label, Reason: fmt.Sprintf("invalid tag key: %v", err)})
+			continue
+		}
+		value, err := e.normalizer.Value(tag.Value)
+		if err != nil {
+			result.InvalidTags = append(result.InvalidTags, InvalidTag{Label: tag.Label, Reason: fmt.Sprintf("invalid tag value: %v", err)})
+			continue
+		}
+		if label, found := seen[key]; found {
+			result.InvalidTags = append(result.InvalidTags, InvalidTag{Label: tag.Label, Reason: fmt.Sprintf("tag key %s is already used for label %s", key, label)})
+			continue
+		}
+		seen[key] = tag.Label
+		tag.Key, tag.Value = key, value
+		result.Tags = append(result.Tags, tag)
+	}
+	sort.Slice(result.Tags, func(i, j int) bool { return result.Tags[i].Key < result.Tags[j].Key })
+	return result
+}
+
+// ManagesTagKey reports whether a tag key may have been created for the policies, so it can be garbage collected.
+// Keys produced by renames using capture groups cannot be recognized and are never reported.
+func (e *Evaluator) ManagesTagKey(ctx context.Context, key string) (bool, error) {
+	policies, err := e.policies(ctx)
+	if err != nil {
+		return false, err
+	}
+	if len(policies) == 0 {
+		return len(e.fallback(map[string]string{key: ""})) > 0, nil
+	}
+
+	for _, policy := range policies {
+		for _, rule := range policy.Spec.Rules {
+			switch {
+			case rule.TagKey != "":
+				if !strings.Contains(rule.TagKey, "$") && rule.TagKey == key {
+					return true, nil
+				}
+			case rule.Label != "":
+				if rule.Label == key {
+					return true, nil
+				}
+			default:
+				re, err := e.regexp(rule.LabelRegex)
+				if err != nil {
+					continue
+				}
+				if re.MatchString(key) {
+					return true, nil
+				}
+			}
+		}
+	}
+	return false, nil
+}
+
+func (e *Evaluator) policies(ctx context.Context) ([]taggingv1alpha1.TaggingPolicy, error) {
+	var list taggingv1alpha1.TaggingPolicyList
+	if err := e.reader.List(ctx, &list); err != nil {
+		return nil, fmt.Errorf("failed to list tagging policies: %w", err)
+	}
+	sort.Slice(list.Items, func(i, j int) bool { return list.Items[i].Name < list.Items[j].Name })
+	return list.Items, nil
+}
+
+// namespaceDefaults returns the filtered labels and annotations of a namespace, which serve as default labels of its resources.
+func (e *Evaluator) namespaceDefaults(ctx context.Context, namespace string) (map[string]string, error) {
+	if !e.UsesNamespaces() || namespace == "" {
+		return nil, nil
+	}
+
+	var ns corev1.Namespace
+	if err := e.reader.Get(ctx, types.NamespacedName{Name: namespace}, &ns); err != nil {
+		return nil, fmt.Errorf("failed to get namespace %s: %w", namespace, err)
+	}
+
+	defaults := make(map[string]string)
+	if e.namespaceAnnotations != nil {
+		defaults = mergeLabels(defaults, e.namespaceAnnotations(ns.Annotations))
+	}
+	if e.namespaceLabels != nil {
+		defaults = mergeLabels(defaults, e.namespaceLabels(ns.Labels))
+	}
+	return defaults, nil
+}
+
+// mergeLabels returns the union of both label sets, where overrides take precedence over defaults.
+func mergeLabels(defaults, overrides map[string]string) map[string]string {
+	merged := make(map[string]string, len(defaults)+len(overrides))
+	for k, v := range defaults {
+		merged[k] = v
+	}
+	for k, v := range overrides {
+		merged[k] = v
+	}
+	return merged
+}
+
+func (e *Evaluator) evaluateFallback(matched map[string]string) *Result {
+	result := &Result{}
+	for _, k := range sortedKeys(matched) {
+		result.Tags = append(result.Tags, Tag{Key: k, Value: matched[k], Label: k})
+	}
+	return result
+}
+
+func (e *Evaluator) evaluateRule(rule taggingv1alpha1.TagRule, labels map[string]string) ([]Tag, error) {
+	if rule.Label != "" {
+		value, found := labels[rule.Label]
+		if !found {
+			return nil, nil
+		}
+		key := rule.TagKey
+		if key == "" {
+			key = rule.Label
+		}
+		return []Tag{{Key: key, Value: transformValue(rule.ValueTransform, value), Label: rule.Label}}, nil
+	}
+
+	re, err := e.regexp(rule.LabelRegex)
+	if err != nil {
+		return nil, err
+	}
+	var tags []Tag
+	for _, label := range sortedKeys(labels) {
+		match := re.FindStringSubmatchIndex(label)
+		if match == nil {
+			continue
+		}
+		key := label
+		if rule.TagKey != "" {
+			key = string(re.ExpandString(nil, rule.TagKey, label, match))
+		}
+		tags = append(tags, Tag{Key: key, Value: transformValue(rule.ValueTransform, labels[label]), Label: label})
+	}
+	return tags, nil
+}
+
+// regexp compiles a label regex anchored to the whole label key. Compiled expressions are reused.
+func (e *Evaluator) regexp(expr string) (*regexp.Regexp, error) {
+	if re, found := e.regexps.Load(expr); found {
+		return re.(*regexp.Regexp), nil
+	}
+	re, err := regexp.Compile("^(?:" + expr + ")$")
+	if err != nil {
+		return nil, err
+	}
+	e.regexps.Store(expr, re)
+	return re, nil
+}
+
+// ValidateRule checks a rule for errors the CRD schema cannot express.
+func ValidateRule(rule taggingv1alpha1.TagRule) error {
+	if (rule.Label == "") == (rule.LabelRegex == "") {
+		return fmt.Errorf("exactly one of label and labelRegex must be set")
+	}
+	if rule.LabelRegex != "" {
+		if _, err := regexp.Compile("^(?:" + rule.LabelRegex + ")$"); err != nil {
+			return fmt.Errorf("invalid labelRegex: %w", err)
+		}
+	} else if strings.Contains(rule.TagKey, "$") {
+		return fmt.Errorf("tagKey can only reference capture groups of labelRegex")
+	}
+	return nil
+}
+
+func ruleApplies(rule taggingv1alpha1.TagRule, kind, namespace string) bool {
+	if len(rule.Kinds) > 0 && !slices.Contains(rule.Kinds, kind) {
+		return false
+	}
+	if len(rule.Namespaces) > 0 && !slices.Contains(rule.Namespaces, namespace) {
+		return false
+	}
+	return true
+}
+
+func ruleLabel(rule taggingv1alpha1.TagRule) string {
+	if rule.Label != "" {
+		return rule.Label
+	}
+	return rule.LabelRegex
+}
+
+func transformValue(transform taggingv1alpha1.ValueTransform, value string) string {
+	switch transform {
+	case taggingv1alpha1.ValueTransformLowercase:
+		return strings.ToLower(value)
+	case taggingv1alpha1.ValueTransformUppercase:
+		return strings.ToUpper(value)
+	default:
+		return value
+	}
+}
+
+func sortedKeys(in map[string]string) []string {
+	keys := make([]string, 0, len(in))
+	for k := range in {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
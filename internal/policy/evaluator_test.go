@@ -0,0 +1,268 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package policy
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	taggingv1alpha1 "github.com/deliveryhero/gcp-config-connector-tagging-operator/api/v1alpha1"
+	"github.com/deliveryhero/gcp-config-connector-tagging-operator/internal/util"
+)
+
+func newTestEvaluator(t *testing.T, policies ...*taggingv1alpha1.TaggingPolicy) *Evaluator {
+	var objs []client.Object
+	for _, p := range policies {
+		objs = append(objs, p)
+	}
+	return newTestEvaluatorWithObjects(t, objs)
+}
+
+func newTestEvaluatorWithObjects(t *testing.T, objs []client.Object, opts ...Option) *Evaluator {
+	scheme := runtime.NewScheme()
+	assert.NoError(t, corev1.AddToScheme(scheme))
+	assert.NoError(t, taggingv1alpha1.AddToScheme(scheme))
+	builder := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...)
+	fallback, err := util.LimitLabelsWithRegex("^team$")
+	assert.NoError(t, err)
+	return NewEvaluator(builder.Build(), fallback, opts...)
+}
+
+func newTestResource(kind, namespace string, labels map[string]string) client.Object {
+	obj := &metav1.PartialObjectMetadata{
+		ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: namespace, Labels: labels},
+	}
+	obj.SetGroupVersionKind(schema.GroupVersionKind{Group: "storage.cnrm.cloud.google.com", Version: "v1beta1", Kind: kind})
+	return obj
+}
+
+func newTestPolicy(name string, rules ...taggingv1alpha1.TagRule) *taggingv1alpha1.TaggingPolicy {
+	return &taggingv1alpha1.TaggingPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec:       taggingv1alpha1.TaggingPolicySpec{Rules: rules},
+	}
+}
+
+func TestEvaluateFallback(t *testing.T) {
+	e := newTestEvaluator(t)
+	result, err := e.Evaluate(context.Background(), newTestResource("StorageBucket", "default", map[string]string{"team": "payments", "env": "prod"}))
+	assert.NoError(t, err)
+	assert.Equal(t, []Tag{{Key: "team", Value: "payments", Label: "team"}}, result.Tags)
+	assert.Empty(t, result.MissingLabels)
+}
+
+func TestEvaluatePolicies(t *testing.T) {
+	tests := []struct {
+		name     string
+		policies []*taggingv1alpha1.TaggingPolicy
+		resource client.Object
+		expected *Result
+	}{
+		{
+			name: "rename and transform",
+			policies: []*taggingv1alpha1.TaggingPolicy{newTestPolicy("p",
+				taggingv1alpha1.TagRule{Label: "team", TagKey: "owner", ValueTransform: taggingv1alpha1.ValueTransformLowercase},
+			)},
+			resource: newTestResource("StorageBucket", "default", map[string]string{"team": "Payments", "env": "prod"}),
+			expected: &Result{Tags: []Tag{{Key: "owner", Value: "payments", Label: "team", Policy: "p"}}},
+		},
+		{
+			name: "regex with capture groups",
+			policies: []*taggingv1alpha1.TaggingPolicy{newTestPolicy("p",
+				taggingv1alpha1.TagRule{LabelRegex: "tag-(.*)", TagKey: "${1}"},
+			)},
+			resource: newTestResource("StorageBucket", "default", map[string]string{"tag-team": "payments", "tag-env": "prod", "my-tag-x": "y"}),
+			expected: &Result{Tags: []Tag{
+				{Key: "env", Value: "prod", Label: "tag-env", Policy: "p"},
+				{Key: "team", Value: "payments", Label: "tag-team", Policy: "p"},
+			}},
+		},
+		{
+			name: "kinds and namespaces",
+			policies: []*taggingv1alpha1.TaggingPolicy{newTestPolicy("p",
+				taggingv1alpha1.TagRule{Label: "team", Kinds: []string{"SQLInstance"}},
+				taggingv1alpha1.TagRule{Label: "env", Namespaces: []string{"default"}},
+				taggingv1alpha1.TagRule{Label: "cost-center", Namespaces: []string{"other"}},
+			)},
+			resource: newTestResource("StorageBucket", "default", map[string]string{"team": "payments", "env": "prod", "cost-center": "42"}),
+			expected: &Result{Tags: []Tag{{Key: "env", Value: "prod", Label: "env", Policy: "p"}}},
+		},
+		{
+			name: "required labels",
+			policies: []*taggingv1alpha1.TaggingPolicy{newTestPolicy("p",
+				taggingv1alpha1.TagRule{Label: "team", Required: true},
+				taggingv1alpha1.TagRule{LabelRegex: "cost-.*", Required: true},
+				taggingv1alpha1.TagRule{Label: "env", Required: true, Kinds: []string{"SQLInstance"}},
+			)},
+			resource: newTestResource("StorageBucket", "default", map[string]string{"team": "payments"}),
+			expected: &Result{
+				Tags:          []Tag{{Key: "team", Value: "payments", Label: "team", Policy: "p"}},
+				MissingLabels: []string{"cost-.*"},
+			},
+		},
+		{
+			name: "first policy wins",
+			policies: []*taggingv1alpha1.TaggingPolicy{
+				newTestPolicy("b", taggingv1alpha1.TagRule{Label: "team", TagKey: "owner"}),
+				newTestPolicy("a", taggingv1alpha1.TagRule{Label: "owner"}),
+			},
+			resource: newTestResource("StorageBucket", "default", map[string]string{"team": "payments", "owner": "search"}),
+			expected: &Result{Tags: []Tag{{Key: "owner", Value: "search", Label: "owner", Policy: "a"}}},
+		},
+		{
+			name: "invalid rules are skipped",
+			policies: []*taggingv1alpha1.TaggingPolicy{newTestPolicy("p",
+				taggingv1alpha1.TagRule{LabelRegex: "("},
+				taggingv1alpha1.TagRule{Label: "team"},
+			)},
+			resource: newTestResource("StorageBucket", "default", map[string]string{"team": "payments"}),
+			expected: &Result{Tags: []Tag{{Key: "team", Value: "payments", Label: "team", Policy: "p"}}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := newTestEvaluator(t, tt.policies...).Evaluate(context.Background(), tt.resource)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestEvaluateNamespaceDefaults(t *testing.T) {
+	namespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+		Name:        "default",
+		Labels:      map[string]string{"team": "search", "cost-center": "42", "kubernetes.io/metadata.name": "default"},
+		Annotations: map[string]string{"cost-center": "43", "data-classification": "internal", "other": "x"},
+	}}
+	labels, err := util.LimitLabelsWithRegex("^(team|cost-center)$")
+	assert.NoError(t, err)
+	annotations, err := util.LimitLabelsWithRegex("^(cost-center|data-classification)$")
+	assert.NoError(t, err)
+	opts := []Option{WithNamespaceLabels(labels), WithNamespaceAnnotations(annotations)}
+	resource := newTestResource("StorageBucket", "default", map[string]string{"team": "payments", "env": "prod"})
+
+	t.Run("fallback", func(t *testing.T) {
+		e := newTestEvaluatorWithObjects(t, []client.Object{namespace}, opts...)
+		assert.True(t, e.UsesNamespaces())
+		result, err := e.Evaluate(context.Background(), resource)
+		assert.NoError(t, err)
+		assert.Equal(t, []Tag{
+			{Key: "cost-center", Value: "42", Label: "cost-center"},
+			{Key: "data-classification", Value: "internal", Label: "data-classification"},
+			{Key: "team", Value: "payments", Label: "team"},
+		}, result.Tags)
+	})
+
+	t.Run("policies", func(t *testing.T) {
+		e := newTestEvaluatorWithObjects(t, []client.Object{namespace, newTestPolicy("p",
+			taggingv1alpha1.TagRule{Label: "team"},
+			taggingv1alpha1.TagRule{Label: "cost-center", TagKey: "cost"},
+		)}, opts...)
+		result, err := e.Evaluate(context.Background(), resource)
+		assert.NoError(t, err)
+		assert.Equal(t, []Tag{
+			{Key: "cost", Value: "42", Label: "cost-center", Policy: "p"},
+			{Key: "team", Value: "payments", Label: "team", Policy: "p"},
+		}, result.Tags)
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		e := newTestEvaluatorWithObjects(t, []client.Object{namespace})
+		assert.False(t, e.UsesNamespaces())
+		result, err := e.Evaluate(context.Background(), resource)
+		assert.NoError(t, err)
+		assert.Equal(t, []Tag{{Key: "team", Value: "payments", Label: "team"}}, result.Tags)
+	})
+}
+
+func TestEvaluateNormalization(t *testing.T) {
+	resource := newTestResource("StorageBucket", "default", map[string]string{
+		"app.kubernetes.io/name": "api",
+		"example.com/name":       "other",
+		"team":                   "",
+	})
+
+	t.Run("validation only", func(t *testing.T) {
+		e := newTestEvaluatorWithObjects(t, nil)
+		e.fallback = func(in map[string]string) map[string]string { return in }
+		result, err := e.Evaluate(context.Background(), resource)
+		assert.NoError(t, err)
+		assert.Empty(t, result.Tags)
+		assert.Len(t, result.InvalidTags, 3)
+	})
+
+	t.Run("strip prefix", func(t *testing.T) {
+		e := newTestEvaluatorWithObjects(t, nil, WithNormalizer(&util.ShortNameNormalizer{StripPrefix: true}))
+		e.fallback = func(in map[string]string) map[string]string { return in }
+		result, err := e.Evaluate(context.Background(), resource)
+		assert.NoError(t, err)
+		assert.Equal(t, []Tag{{Key: "name", Value: "api", Label: "app.kubernetes.io/name"}}, result.Tags)
+		assert.Equal(t, []InvalidTag{
+			{Label: "example.com/name", Reason: "tag key name is already used for label app.kubernetes.io/name"},
+			{Label: "team", Reason: "invalid tag value: short name must not be empty"},
+		}, result.InvalidTags)
+	})
+}
+
+func TestManagesTagKey(t *testing.T) {
+	ctx := context.Background()
+
+	fallback := newTestEvaluator(t)
+	managed, err := fallback.ManagesTagKey(ctx, "team")
+	assert.NoError(t, err)
+	assert.True(t, managed)
+	managed, err = fallback.ManagesTagKey(ctx, "env")
+	assert.NoError(t, err)
+	assert.False(t, managed)
+
+	e := newTestEvaluator(t, newTestPolicy("p",
+		taggingv1alpha1.TagRule{Label: "team", TagKey: "owner"},
+		taggingv1alpha1.TagRule{Label: "env"},
+		taggingv1alpha1.TagRule{LabelRegex: "cost-.*"},
+		taggingv1alpha1.TagRule{LabelRegex: "tag-(.*)", TagKey: "${1}"},
+	))
+	for key, expected := range map[string]bool{
+		"owner":       true,
+		"team":        false,
+		"env":         true,
+		"cost-center": true,
+		"tag-x":       false,
+		"x":           false,
+	} {
+		managed, err := e.ManagesTagKey(ctx, key)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, managed, key)
+	}
+}
+
+func TestValidateRule(t *testing.T) {
+	assert.NoError(t, ValidateRule(taggingv1alpha1.TagRule{Label: "team"}))
+	assert.NoError(t, ValidateRule(taggingv1alpha1.TagRule{LabelRegex: "tag-(.*)", TagKey: "${1}"}))
+	assert.Error(t, ValidateRule(taggingv1alpha1.TagRule{}))
+	assert.Error(t, ValidateRule(taggingv1alpha1.TagRule{Label: "team", LabelRegex: "team"}))
+	assert.Error(t, ValidateRule(taggingv1alpha1.TagRule{LabelRegex: "("}))
+	assert.Error(t, ValidateRule(taggingv1alpha1.TagRule{Label: "team", TagKey: "${1}"}))
+}
@@ -0,0 +1,108 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package policy
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// TagAnnotationPrefix prefixes annotations declaring a single tag, e.g. "tags.gdp.deliveryhero.io/team: payments".
+	TagAnnotationPrefix = "tags.gdp.deliveryhero.io/"
+	// TagsAnnotation declares several tags as JSON object, e.g. `gdp.deliveryhero.io/tags: {"team": "payments"}`.
+	TagsAnnotation = "gdp.deliveryhero.io/tags"
+)
+
+// Source is a part of a resource's metadata tags are read from.
+type Source string
+
+const (
+	// SourceLabels reads tags from the labels of a resource.
+	SourceLabels Source = "labels"
+	// SourceAnnotations reads tags from the TagsAnnotation and annotations prefixed with TagAnnotationPrefix.
+	SourceAnnotations Source = "annotations"
+)
+
+// ParseSources parses a comma separated list of sources.
+func ParseSources(s string) ([]Source, error) {
+	var sources []Source
+	for _, part := range strings.Split(s, ",") {
+		source := Source(strings.TrimSpace(part))
+		switch source {
+		case SourceLabels, SourceAnnotations:
+			sources = append(sources, source)
+		case "":
+		default:
+			return nil, fmt.Errorf("unknown tag source %q, expected %q or %q", source, SourceLabels, SourceAnnotations)
+		}
+	}
+	if len(sources) == 0 {
+		return nil, fmt.Errorf("at least one tag source is required")
+	}
+	return sources, nil
+}
+
+// WithSources sets the parts of a resource's metadata tags are read from, by default only its labels.
+// Later sources take precedence over earlier ones.
+func WithSources(sources ...Source) Option {
+	return func(e *Evaluator) {
+		e.sources = sources
+	}
+}
+
+// resourceLabels collects the labels feeding the tags of a resource from all sources. A malformed TagsAnnotation
+// is reported as invalid tag instead of failing the evaluation, as retrying cannot help until it is fixed.
+func (e *Evaluator) resourceLabels(resource client.Object) (map[string]string, []InvalidTag) {
+	labels := make(map[string]string)
+	var invalid []InvalidTag
+	for _, source := range e.sources {
+		switch source {
+		case SourceLabels:
+			labels = mergeLabels(labels, resource.GetLabels())
+		case SourceAnnotations:
+			tags, err := annotationTags(resource.GetAnnotations())
+			if err != nil {
+				invalid = append(invalid, InvalidTag{Label: TagsAnnotation, Reason: err.Error()})
+			}
+			labels = mergeLabels(labels, tags)
+		}
+	}
+	return labels, invalid
+}
+
+// annotationTags returns the tags declared by annotations. Annotations of single tags take precedence over the TagsAnnotation.
+// If the TagsAnnotation is malformed, the tags of single tag annotations are returned along with the error.
+func annotationTags(annotations map[string]string) (map[string]string, error) {
+	var err error
+	tags := make(map[string]string)
+	if raw, found := annotations[TagsAnnotation]; found {
+		if jsonErr := json.Unmarshal([]byte(raw), &tags); jsonErr != nil {
+			err = fmt.Errorf("invalid %s annotation, expected a JSON object of strings: %w", TagsAnnotation, jsonErr)
+			tags = make(map[string]string)
+		}
+	}
+	for k, v := range annotations {
+		if key, found := strings.CutPrefix(k, TagAnnotationPrefix); found && key != "" {
+			tags[key] = v
+		}
+	}
+	return tags, err
+}
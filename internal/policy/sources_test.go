@@ -0,0 +1,118 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package policy
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	taggingv1alpha1 "github.com/deliveryhero/gcp-config-connector-tagging-operator/api/v1alpha1"
+)
+
+func TestParseSources(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    []Source
+		wantErr bool
+	}{
+		{name: "labels", input: "labels", want: []Source{SourceLabels}},
+		{name: "both", input: "labels, annotations", want: []Source{SourceLabels, SourceAnnotations}},
+		{name: "unknown", input: "labels,spec", wantErr: true},
+		{name: "empty", input: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseSources(tt.input)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestEvaluateSources(t *testing.T) {
+	resource := func(annotations map[string]string) client.Object {
+		obj := newTestResource("StorageBucket", "default", map[string]string{"team": "payments", "env": "prod"})
+		obj.SetAnnotations(annotations)
+		return obj
+	}
+
+	tests := []struct {
+		name        string
+		sources     []Source
+		policies    []client.Object
+		annotations map[string]string
+		want        []Tag
+		// wantInvalid are the labels of the expected invalid tags
+		wantInvalid []string
+	}{
+		{
+			name:        "labels only by default",
+			annotations: map[string]string{TagAnnotationPrefix + "team": "search"},
+			want:        []Tag{{Key: "team", Value: "payments", Label: "team"}},
+		},
+		{
+			name:        "annotations take precedence over labels",
+			sources:     []Source{SourceLabels, SourceAnnotations},
+			annotations: map[string]string{TagAnnotationPrefix + "team": "search"},
+			want:        []Tag{{Key: "team", Value: "search", Label: "team"}},
+		},
+		{
+			name:        "single tag annotations take precedence over the JSON annotation",
+			sources:     []Source{SourceAnnotations},
+			policies:    []client.Object{newTestPolicy("p", taggingv1alpha1.TagRule{LabelRegex: ".*"})},
+			annotations: map[string]string{TagsAnnotation: `{"team": "search", "cost-center": "42"}`, TagAnnotationPrefix + "team": "payments-platform"},
+			want: []Tag{
+				{Key: "cost-center", Value: "42", Label: "cost-center", Policy: "p"},
+				{Key: "team", Value: "payments-platform", Label: "team", Policy: "p"},
+			},
+		},
+		{
+			name:        "invalid JSON annotation",
+			sources:     []Source{SourceLabels, SourceAnnotations},
+			annotations: map[string]string{TagsAnnotation: `{"team": 1}`, TagAnnotationPrefix + "team": "search"},
+			want:        []Tag{{Key: "team", Value: "search", Label: "team"}},
+			wantInvalid: []string{TagsAnnotation},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var opts []Option
+			if tt.sources != nil {
+				opts = append(opts, WithSources(tt.sources...))
+			}
+			e := newTestEvaluatorWithObjects(t, tt.policies, opts...)
+			result, err := e.Evaluate(context.Background(), resource(tt.annotations))
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, result.Tags)
+			var invalid []string
+			for _, tag := range result.InvalidTags {
+				invalid = append(invalid, tag.Label)
+			}
+			assert.Equal(t, tt.wantInvalid, invalid)
+		})
+	}
+}
@@ -0,0 +1,93 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// MaxShortNameLength is the maximum length of the short name of a tag key or value.
+const MaxShortNameLength = 63
+
+var (
+	// shortNameRegexp matches legal short names of tag keys and values.
+	shortNameRegexp = regexp.MustCompile(`^[A-Za-z0-9]([A-Za-z0-9._-]*[A-Za-z0-9])?$`)
+	// illegalShortNameCharsRegexp matches the characters short names must not contain anywhere.
+	illegalShortNameCharsRegexp = regexp.MustCompile(`[^A-Za-z0-9._-]`)
+)
+
+// ValidateShortName checks a tag key or value short name against the rules of the Resource Manager API:
+// 1 to 63 characters, beginning and ending with [a-zA-Z0-9] and only dashes, underscores and dots in between.
+func ValidateShortName(name string) error {
+	if name == "" {
+		return fmt.Errorf("short name must not be empty")
+	}
+	if n := len(name); n > MaxShortNameLength {
+		return fmt.Errorf("short name %q is %d characters long, at most %d are allowed", name, n, MaxShortNameLength)
+	}
+	if !shortNameRegexp.MatchString(name) {
+		return fmt.Errorf("short name %q must begin and end with [a-zA-Z0-9] and may only contain [a-zA-Z0-9._-]", name)
+	}
+	return nil
+}
+
+// ShortNameNormalizer maps label keys and values to tag key and value short names.
+// The zero value only validates them.
+type ShortNameNormalizer struct {
+	// StripPrefix removes the prefix of label keys up to the last "/", e.g. app.kubernetes.io/name becomes name.
+	StripPrefix bool
+	// Replacement replaces characters short names must not contain, unless empty.
+	Replacement string
+	// Lowercase converts short names to lower case.
+	Lowercase bool
+}
+
+// NewShortNameNormalizer creates a ShortNameNormalizer, making sure the replacement is legal itself.
+func NewShortNameNormalizer(stripPrefix bool, replacement string, lowercase bool) (*ShortNameNormalizer, error) {
+	if illegalShortNameCharsRegexp.MatchString(replacement) {
+		return nil, fmt.Errorf("invalid replacement %q: may only contain [a-zA-Z0-9._-]", replacement)
+	}
+	return &ShortNameNormalizer{StripPrefix: stripPrefix, Replacement: replacement, Lowercase: lowercase}, nil
+}
+
+// Key returns the tag key short name for a label key, or an error if it cannot be mapped to a legal one.
+func (n *ShortNameNormalizer) Key(key string) (string, error) {
+	if n.StripPrefix {
+		key = key[strings.LastIndex(key, "/")+1:]
+	}
+	return n.normalize(key)
+}
+
+// Value returns the tag value short name for a label value, or an error if it cannot be mapped to a legal one.
+func (n *ShortNameNormalizer) Value(value string) (string, error) {
+	return n.normalize(value)
+}
+
+func (n *ShortNameNormalizer) normalize(name string) (string, error) {
+	if n.Replacement != "" {
+		name = illegalShortNameCharsRegexp.ReplaceAllLiteralString(name, n.Replacement)
+	}
+	if n.Lowercase {
+		name = strings.ToLower(name)
+	}
+	if err := ValidateShortName(name); err != nil {
+		return "", err
+	}
+	return name, nil
+}
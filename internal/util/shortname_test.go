@@ -0,0 +1,129 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateShortName(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      string
+		wantErr bool
+	}{
+		{name: "label value", in: "payments-eu_1.0"},
+		{name: "single character", in: "a"},
+		{name: "maximum length", in: strings.Repeat("a", MaxShortNameLength)},
+		{name: "empty", in: "", wantErr: true},
+		{name: "too long", in: strings.Repeat("a", MaxShortNameLength+1), wantErr: true},
+		{name: "space", in: "Payments Team", wantErr: true},
+		{name: "non-ASCII", in: "Zahlungsverkehr-Ö", wantErr: true},
+		{name: "colon", in: "a:b", wantErr: true},
+		{name: "leading dash", in: "-x", wantErr: true},
+		{name: "trailing dot", in: "x.", wantErr: true},
+		{name: "slash", in: "app.kubernetes.io/name", wantErr: true},
+		{name: "quote", in: `it's`, wantErr: true},
+		{name: "backslash", in: `a\b`, wantErr: true},
+		{name: "invalid UTF-8", in: "\xff", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ValidateShortName(tt.in); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateShortName(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestShortNameNormalizer(t *testing.T) {
+	tests := []struct {
+		name       string
+		normalizer ShortNameNormalizer
+		key        string
+		value      string
+		wantKey    string
+		wantValue  string
+		wantErr    bool
+	}{
+		{
+			name:    "validation only",
+			key:     "team",
+			value:   "Payments",
+			wantKey: "team", wantValue: "Payments",
+		},
+		{
+			name:    "prefixed key without normalization",
+			key:     "app.kubernetes.io/name",
+			value:   "api",
+			wantErr: true,
+		},
+		{
+			name:       "strip prefix",
+			normalizer: ShortNameNormalizer{StripPrefix: true},
+			key:        "app.kubernetes.io/name",
+			value:      "api",
+			wantKey:    "name", wantValue: "api",
+		},
+		{
+			name:       "replace and lowercase",
+			normalizer: ShortNameNormalizer{Replacement: "_", Lowercase: true},
+			key:        "app.kubernetes.io/Name",
+			value:      `it's/"Quoted" Team`,
+			wantKey:    "app.kubernetes.io_name", wantValue: "it_s__quoted__team",
+		},
+		{
+			name:       "empty value",
+			normalizer: ShortNameNormalizer{StripPrefix: true, Replacement: "_"},
+			key:        "team",
+			value:      "",
+			wantErr:    true,
+		},
+		{
+			name:       "empty key after stripping the prefix",
+			normalizer: ShortNameNormalizer{StripPrefix: true},
+			key:        "example.com/",
+			value:      "x",
+			wantErr:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key, keyErr := tt.normalizer.Key(tt.key)
+			value, valueErr := tt.normalizer.Value(tt.value)
+			if gotErr := keyErr != nil || valueErr != nil; gotErr != tt.wantErr {
+				t.Fatalf("got errors %v and %v, wantErr %v", keyErr, valueErr, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if key != tt.wantKey || value != tt.wantValue {
+				t.Errorf("got %q=%q, want %q=%q", key, value, tt.wantKey, tt.wantValue)
+			}
+		})
+	}
+}
+
+func TestNewShortNameNormalizer(t *testing.T) {
+	if _, err := NewShortNameNormalizer(true, "-", true); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if _, err := NewShortNameNormalizer(false, " ", false); err == nil {
+		t.Errorf("expected an error for an illegal replacement")
+	}
+}